@@ -24,8 +24,31 @@ const (
 // Options configures the agentkernel client.
 type Options struct {
 	// BaseURL is the agentkernel server URL. Default: http://localhost:8880
+	// Ignored if BaseURLs is set.
 	BaseURL string
 
+	// BaseURLs, when set, puts the client in pool mode: requests are
+	// round-robined across the listed servers, skipping any marked dead by
+	// the healthcheck (see HealthcheckEnabled). Use this behind an HA
+	// agentkernel deployment instead of a separate load balancer.
+	BaseURLs []string
+
+	// HealthcheckEnabled starts a background goroutine that periodically
+	// probes GET /health on every endpoint in BaseURLs and marks it
+	// dead/alive accordingly. Only meaningful when BaseURLs has more than
+	// one entry. Default: false.
+	HealthcheckEnabled bool
+
+	// HealthcheckInterval is how often each endpoint is probed. Default: 10s.
+	HealthcheckInterval time.Duration
+
+	// HealthcheckTimeout bounds each individual healthcheck request. Default: 2s.
+	HealthcheckTimeout time.Duration
+
+	// RetryPolicy configures automatic retries for transient failures.
+	// Nil (the default) disables retrying.
+	RetryPolicy *RetryPolicy
+
 	// APIKey is the optional API key for authentication.
 	APIKey string
 
@@ -34,13 +57,35 @@ type Options struct {
 
 	// HTTPClient overrides the default http.Client. Useful for testing.
 	HTTPClient *http.Client
+
+	// Middleware wraps httpClient.Transport, in the order given, applying
+	// to every request the client makes — including the long-lived
+	// connections RunStream and ExecInteractive hold open. See
+	// RoundTripperMiddleware, WithGzip, WithRequestLogging,
+	// WithOpenTelemetry, and WithRateLimit.
+	Middleware []RoundTripperMiddleware
+
+	// StreamReconnect configures automatic reconnection for RunStream and
+	// SandboxSession.ExecStream when the underlying connection drops
+	// before a terminal event arrives. Default: disabled, so a dropped
+	// stream just ends, with no Type "error" RawStreamEvent for the drop.
+	StreamReconnect StreamReconnectPolicy
+
+	// CacheStore backs Client.Cache. Default: a filesystem store rooted
+	// at $XDG_CACHE_HOME/agentkernel.
+	CacheStore CacheStore
 }
 
 // Client is the agentkernel API client.
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	apiKey          string
+	httpClient      *http.Client
+	pool            *pool
+	retryPolicy     *RetryPolicy
+	streamReconnect StreamReconnectPolicy
+
+	// Cache memoizes expensive sandbox work. See the Cache type.
+	Cache *Cache
 }
 
 // New creates a new agentkernel client.
@@ -54,9 +99,16 @@ type Client struct {
 //	})
 func New(opts *Options) *Client {
 	baseURL := defaultBaseURL
+	var baseURLs []string
 	apiKey := ""
 	timeout := defaultTimeout
 	var httpClient *http.Client
+	healthcheckEnabled := false
+	var healthcheckInterval, healthcheckTimeout time.Duration
+	var retryPolicy *RetryPolicy
+	var middleware []RoundTripperMiddleware
+	var streamReconnect StreamReconnectPolicy
+	var cacheStore CacheStore
 
 	// Env vars
 	if v := os.Getenv("AGENTKERNEL_BASE_URL"); v != "" {
@@ -71,6 +123,9 @@ func New(opts *Options) *Client {
 		if opts.BaseURL != "" {
 			baseURL = opts.BaseURL
 		}
+		if len(opts.BaseURLs) > 0 {
+			baseURLs = opts.BaseURLs
+		}
 		if opts.APIKey != "" {
 			apiKey = opts.APIKey
 		}
@@ -78,17 +133,64 @@ func New(opts *Options) *Client {
 			timeout = opts.Timeout
 		}
 		httpClient = opts.HTTPClient
+		healthcheckEnabled = opts.HealthcheckEnabled
+		healthcheckInterval = opts.HealthcheckInterval
+		healthcheckTimeout = opts.HealthcheckTimeout
+		retryPolicy = opts.RetryPolicy
+		middleware = opts.Middleware
+		streamReconnect = opts.StreamReconnect
+		cacheStore = opts.CacheStore
+	}
+	if cacheStore == nil {
+		cacheStore = newFilesystemCacheStore()
+	}
+	if streamReconnect.Enabled && streamReconnect.Backoff <= 0 {
+		streamReconnect.Backoff = time.Second
 	}
 
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: timeout}
 	}
+	if len(middleware) > 0 {
+		transport := httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		cloned := *httpClient
+		cloned.Transport = chainMiddleware(transport, middleware)
+		httpClient = &cloned
+	}
+
+	if len(baseURLs) == 0 {
+		baseURLs = []string{baseURL}
+	}
+	normalized := make([]string, len(baseURLs))
+	for i, u := range baseURLs {
+		normalized[i] = strings.TrimRight(u, "/")
+	}
 
-	return &Client{
-		baseURL:    strings.TrimRight(baseURL, "/"),
-		apiKey:     apiKey,
-		httpClient: httpClient,
+	client := &Client{
+		apiKey:          apiKey,
+		httpClient:      httpClient,
+		pool:            newPool(normalized, healthcheckEnabled, healthcheckInterval, healthcheckTimeout, httpClient),
+		retryPolicy:     retryPolicy,
+		streamReconnect: streamReconnect,
 	}
+	client.Cache = &Cache{client: client, store: cacheStore}
+	return client
+}
+
+// Endpoints returns the current health status of every configured server,
+// in pool order. Useful for observability dashboards and readiness checks.
+func (c *Client) Endpoints() []EndpointStatus {
+	return c.pool.statuses()
+}
+
+// Close stops the background healthcheck goroutine, if one is running.
+// It is safe to call Close on a client created without HealthcheckEnabled.
+func (c *Client) Close() error {
+	c.pool.close()
+	return nil
 }
 
 // Health returns "ok" if the server is healthy.
@@ -108,21 +210,42 @@ func (c *Client) Run(ctx context.Context, command []string, opts *RunOptions) (*
 		Command: command,
 		Fast:    fast,
 	}
+	var cacheKey string
 	if opts != nil {
 		body.Image = opts.Image
 		body.Profile = opts.Profile
+		body.Policy = opts.Policy
+		cacheKey = opts.CacheKey
 	}
-	var result RunOutput
-	err := c.request(ctx, http.MethodPost, "/run", body, &result)
+	ctx = withRequestInfo(ctx, "", command)
+
+	if cacheKey == "" {
+		return c.doRun(ctx, body)
+	}
+
+	key := runCacheKey(cacheKey, body.Image, command)
+	if cached, ok := c.Cache.getRunOutput(ctx, key); ok {
+		return cached, nil
+	}
+	result, err := c.doRun(ctx, body)
 	if err != nil {
 		return nil, err
 	}
+	c.Cache.putRunOutput(ctx, key, result)
+	return result, nil
+}
+
+func (c *Client) doRun(ctx context.Context, body runRequest) (*RunOutput, error) {
+	var result RunOutput
+	if err := c.request(ctx, http.MethodPost, "/run", body, &result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
 // RunStream executes a command with SSE streaming output.
-// Returns a channel of StreamEvent. The channel is closed when the stream ends.
-func (c *Client) RunStream(ctx context.Context, command []string, opts *RunOptions) (<-chan StreamEvent, error) {
+// Returns a channel of RawStreamEvent. The channel is closed when the stream ends.
+func (c *Client) RunStream(ctx context.Context, command []string, opts *RunOptions) (<-chan RawStreamEvent, error) {
 	fast := true
 	if opts != nil && opts.Fast != nil {
 		fast = *opts.Fast
@@ -134,41 +257,24 @@ func (c *Client) RunStream(ctx context.Context, command []string, opts *RunOptio
 	if opts != nil {
 		body.Image = opts.Image
 		body.Profile = opts.Profile
+		body.Policy = opts.Policy
 	}
+	ctx = withRequestInfo(ctx, "", command)
 
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
-		c.baseURL+"/run/stream", bytes.NewReader(jsonBody))
-	if err != nil {
-		return nil, err
+	dial := func(ctx context.Context, lastEventID string) (*http.Response, error) {
+		return c.dialSSE(ctx, "/run/stream", jsonBody, lastEventID)
 	}
-	c.applyHeaders(req)
-	req.Header.Set("Accept", "text/event-stream")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := dial(ctx, "")
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		return nil, c.handleErrorResponse(resp)
-	}
 
-	// ParseSSE closes the channel when the body is exhausted.
-	// Wrap the body so it gets closed when parsing is done.
-	ch := make(chan StreamEvent)
-	go func() {
-		defer close(ch)
-		defer resp.Body.Close()
-		for event := range ParseSSE(resp.Body) {
-			ch <- event
-		}
-	}()
-	return ch, nil
+	return c.streamWithReconnect(ctx, resp, dial), nil
 }
 
 // ListSandboxes returns all sandboxes.
@@ -183,6 +289,10 @@ func (c *Client) CreateSandbox(ctx context.Context, name string, opts *CreateSan
 	body := createRequest{Name: name}
 	if opts != nil {
 		body.Image = opts.Image
+		body.VCPUs = opts.VCPUs
+		body.MemoryMB = opts.MemoryMB
+		body.Profile = opts.Profile
+		body.Policy = opts.Policy
 	}
 	var result SandboxInfo
 	err := c.request(ctx, http.MethodPost, "/sandboxes", body, &result)
@@ -211,6 +321,7 @@ func (c *Client) RemoveSandbox(ctx context.Context, name string) error {
 // ExecInSandbox executes a command in an existing sandbox.
 func (c *Client) ExecInSandbox(ctx context.Context, name string, command []string) (*RunOutput, error) {
 	body := execRequest{Command: command}
+	ctx = withRequestInfo(ctx, name, command)
 	var result RunOutput
 	err := c.request(ctx, http.MethodPost, "/sandboxes/"+name+"/exec", body, &result)
 	if err != nil {
@@ -219,6 +330,32 @@ func (c *Client) ExecInSandbox(ctx context.Context, name string, command []strin
 	return &result, nil
 }
 
+// ExecStream executes a command in an existing sandbox with SSE streaming
+// output, the exec counterpart to RunStream. It hits a distinct endpoint
+// from ExecInteractive's POST /sandboxes/{name}/exec/stream: that one
+// speaks newline-delimited JSON over a bidirectional chunked body for
+// live stdin, while this one is a plain request/SSE-response stream for a
+// command that runs to completion unattended.
+func (c *Client) ExecStream(ctx context.Context, name string, command []string) (<-chan RawStreamEvent, error) {
+	ctx = withRequestInfo(ctx, name, command)
+
+	jsonBody, err := json.Marshal(execRequest{Command: command})
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/sandboxes/" + name + "/exec/events"
+	dial := func(ctx context.Context, lastEventID string) (*http.Response, error) {
+		return c.dialSSE(ctx, path, jsonBody, lastEventID)
+	}
+	resp, err := dial(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return c.streamWithReconnect(ctx, resp, dial), nil
+}
+
 // WithSandbox creates a sandbox, passes a SandboxSession to fn, and removes
 // the sandbox when fn returns — even if fn returns an error.
 func (c *Client) WithSandbox(ctx context.Context, name string, opts *CreateSandboxOptions, fn func(session *SandboxSession) error) error {
@@ -243,55 +380,110 @@ func (c *Client) applyHeaders(req *http.Request) {
 }
 
 func (c *Client) request(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	var bodyReader io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("agentkernel: marshal request: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
-	if err != nil {
-		return fmt.Errorf("agentkernel: create request: %w", err)
-	}
-	c.applyHeaders(req)
+	maxRetries := c.retryPolicy.maxRetries()
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		err, retryAfterHint, retryable := c.requestAttempt(ctx, method, path, jsonBody, result)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable || attempt >= maxRetries {
+			return lastErr
+		}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("agentkernel: %w", err)
-	}
-	defer resp.Body.Close()
+		delay := retryAfterHint
+		if delay <= 0 {
+			delay = c.retryPolicy.backoffFor(attempt + 1)
+		}
+		c.retryPolicy.onRetry(attempt+1, lastErr, delay)
 
-	if resp.StatusCode >= 400 {
-		return c.handleErrorResponse(resp)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
 	}
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("agentkernel: read response: %w", err)
-	}
+// requestAttempt makes one pass over the endpoint pool for a single logical
+// request, failing over between endpoints on connection errors and
+// retryable status codes. It reports whether the final error is retryable
+// and, if the server sent one, the delay from its Retry-After header.
+func (c *Client) requestAttempt(ctx context.Context, method, path string, jsonBody []byte, result interface{}) (err error, retryAfterHint time.Duration, retryable bool) {
+	var lastErr error
+	lastRetryable := false
+	var lastRetryAfter time.Duration
+
+	for _, ep := range c.pool.candidates() {
+		var bodyReader io.Reader
+		if jsonBody != nil {
+			bodyReader = bytes.NewReader(jsonBody)
+		}
 
-	var apiResp apiResponse[json.RawMessage]
-	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		return fmt.Errorf("agentkernel: decode response: %w", err)
-	}
+		req, reqErr := http.NewRequestWithContext(ctx, method, ep.baseURL+path, bodyReader)
+		if reqErr != nil {
+			return fmt.Errorf("agentkernel: create request: %w", reqErr), 0, false
+		}
+		c.applyHeaders(req)
 
-	if !apiResp.Success {
-		msg := apiResp.Error
-		if msg == "" {
-			msg = "unknown error"
+		resp, doErr := c.httpClient.Do(req)
+		if doErr != nil {
+			lastErr = fmt.Errorf("agentkernel: %w", doErr)
+			lastRetryable = true
+			continue
 		}
-		return &Error{StatusCode: resp.StatusCode, Message: msg}
-	}
 
-	if result != nil && apiResp.Data != nil {
-		if err := json.Unmarshal(apiResp.Data, result); err != nil {
-			return fmt.Errorf("agentkernel: decode data: %w", err)
+		if resp.StatusCode >= 400 {
+			apiErr := c.handleErrorResponse(resp)
+			resp.Body.Close()
+			if isRetryableStatus(resp.StatusCode) || c.retryPolicy.isRetryableStatusCode(resp.StatusCode) {
+				lastErr = apiErr
+				lastRetryable = true
+				if d, ok := retryAfter(resp); ok {
+					lastRetryAfter = d
+				}
+				continue
+			}
+			return apiErr, 0, false
 		}
+
+		respBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return fmt.Errorf("agentkernel: read response: %w", readErr), 0, false
+		}
+
+		var apiResp apiResponse[json.RawMessage]
+		if err := json.Unmarshal(respBody, &apiResp); err != nil {
+			return fmt.Errorf("agentkernel: decode response: %w", err), 0, false
+		}
+
+		if !apiResp.Success {
+			msg := apiResp.Error
+			if msg == "" {
+				msg = "unknown error"
+			}
+			return &Error{StatusCode: resp.StatusCode, Message: msg}, 0, false
+		}
+
+		if result != nil && apiResp.Data != nil {
+			if err := json.Unmarshal(apiResp.Data, result); err != nil {
+				return fmt.Errorf("agentkernel: decode data: %w", err), 0, false
+			}
+		}
+		return nil, 0, false
 	}
-	return nil
+	return lastErr, lastRetryAfter, lastRetryable
 }
 
 func (c *Client) handleErrorResponse(resp *http.Response) error {
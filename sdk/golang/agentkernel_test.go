@@ -159,6 +159,32 @@ func TestCreateSandbox(t *testing.T) {
 	}
 }
 
+func TestCreateSandboxSendsAllOptions(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		body := readBody(r)
+		if body["vcpus"] != float64(2) {
+			t.Fatalf("expected vcpus 2, got %v", body["vcpus"])
+		}
+		if body["memory_mb"] != float64(512) {
+			t.Fatalf("expected memory_mb 512, got %v", body["memory_mb"])
+		}
+		if body["profile"] != string(ProfileRestrictive) {
+			t.Fatalf("expected profile %s, got %v", ProfileRestrictive, body["profile"])
+		}
+		jsonOK(w, map[string]string{"name": "test-sb", "status": "running", "backend": "docker"})
+	})
+	defer srv.Close()
+
+	_, err := client.CreateSandbox(context.Background(), "test-sb", &CreateSandboxOptions{
+		VCPUs:    2,
+		MemoryMB: 512,
+		Profile:  ProfileRestrictive,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestGetSandbox(t *testing.T) {
 	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.HasSuffix(r.URL.Path, "/sandboxes/my-sb") {
@@ -426,7 +452,7 @@ func TestStreamParsing(t *testing.T) {
 	sse := "event: started\ndata: {\"sandbox\":\"test\"}\n\nevent: output\ndata: {\"content\":\"hello\"}\n\nevent: done\ndata: {\"exit_code\":0}\n\n"
 	ch := ParseSSE(strings.NewReader(sse))
 
-	events := make([]StreamEvent, 0)
+	events := make([]RawStreamEvent, 0)
 	for ev := range ch {
 		events = append(events, ev)
 	}
@@ -447,3 +473,18 @@ func TestStreamParsing(t *testing.T) {
 		t.Fatalf("expected done, got %s", events[2].Type)
 	}
 }
+
+func TestParseSSEHandlesLinesOverScannerLimit(t *testing.T) {
+	big := strings.Repeat("x", 100*1024) // bigger than bufio.Scanner's 64KB default
+	sse := "event: output\ndata: {\"content\":\"" + big + "\"}\n\n"
+
+	ch := ParseSSE(strings.NewReader(sse))
+	ev, ok := <-ch
+	if !ok {
+		t.Fatal("expected one event")
+	}
+	content, _ := ev.Data["content"].(string)
+	if len(content) != len(big) {
+		t.Fatalf("expected content of length %d, got %d", len(big), len(content))
+	}
+}
@@ -0,0 +1,198 @@
+package agentkernel
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CacheStore persists cache entries by key. The default, used when
+// Options.CacheStore is nil, stores each entry as a file under
+// $XDG_CACHE_HOME/agentkernel (see os.UserCacheDir).
+type CacheStore interface {
+	// Get returns the bytes stored under key, or ok == false on a cache
+	// miss. A miss is not an error.
+	Get(ctx context.Context, key string) (data []byte, ok bool, err error)
+	// Put stores data under key, overwriting any existing entry.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Cache memoizes expensive sandbox work — compiles, pip installs, dataset
+// downloads — across Run and WithSandbox calls. See RunOptions.CacheKey to
+// memoize Run itself, and SaveDir/RestoreDir to memoize a directory of
+// build artifacts produced inside a sandbox.
+type Cache struct {
+	client *Client
+	store  CacheStore
+}
+
+// SaveDir lists the files under sandboxPath using the sandbox's manifest,
+// tars their contents through ReadFile, and stores the archive under
+// localKey (the manifest's hashes are not part of the key) for a
+// later RestoreDir — typically in a different, freshly created sandbox —
+// to replay.
+func (c *Cache) SaveDir(ctx context.Context, session *SandboxSession, localKey, sandboxPath string) error {
+	manifest, err := c.client.GetManifest(ctx, session.name, sandboxPath)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range manifest {
+		resp, err := c.client.ReadFile(ctx, session.name, entry.Path)
+		if err != nil {
+			return err
+		}
+		content, err := decodeFileContent(resp)
+		if err != nil {
+			return err
+		}
+
+		name := entry.Path
+		if rel, err := filepath.Rel(sandboxPath, entry.Path); err == nil {
+			name = rel
+		}
+		mode := int64(entry.Mode)
+		if mode == 0 {
+			mode = 0644
+		}
+		hdr := &tar.Header{Name: filepath.ToSlash(name), Mode: mode, Size: int64(len(content))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("agentkernel: write cache tar header for %s: %w", entry.Path, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return fmt.Errorf("agentkernel: write cache tar content for %s: %w", entry.Path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("agentkernel: close cache tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("agentkernel: close cache gzip writer: %w", err)
+	}
+
+	return c.store.Put(ctx, cacheKey(localKey), buf.Bytes())
+}
+
+// RestoreDir looks up localKey and, on a hit, writes every file it
+// contains back into sandboxPath via WriteFile. It reports whether the
+// key was found; a miss is not an error, so callers can fall back to
+// doing the work themselves and calling SaveDir.
+func (c *Cache) RestoreDir(ctx context.Context, session *SandboxSession, localKey, sandboxPath string) (bool, error) {
+	data, ok, err := c.store.Get(ctx, cacheKey(localKey))
+	if err != nil || !ok {
+		return false, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("agentkernel: decode cache entry: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, fmt.Errorf("agentkernel: read cache entry: %w", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return false, fmt.Errorf("agentkernel: read cache entry content: %w", err)
+		}
+		remotePath := filepath.ToSlash(filepath.Join(sandboxPath, hdr.Name))
+		if err := c.client.WriteFile(ctx, session.name, remotePath, string(content), ""); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// getRunOutput looks up a memoized Run result, returning ok == false on a
+// miss or a corrupt entry — either way, the caller should just run the
+// command for real.
+func (c *Cache) getRunOutput(ctx context.Context, key string) (*RunOutput, bool) {
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var out RunOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, false
+	}
+	return &out, true
+}
+
+// putRunOutput memoizes a Run result under key. Failures are swallowed:
+// a cache write failing shouldn't fail the Run call it's memoizing.
+func (c *Cache) putRunOutput(ctx context.Context, key string, out *RunOutput) {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return
+	}
+	c.store.Put(ctx, key, data) //nolint:errcheck
+}
+
+// cacheKey derives a CacheStore key from a caller-supplied fingerprint.
+// Hashing keeps filesystemCacheStore's filenames short and filesystem-safe
+// regardless of what characters localKey contains.
+func cacheKey(localKey string) string {
+	sum := sha256.Sum256([]byte(localKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// runCacheKey fingerprints a Run call's cacheable inputs: the caller's
+// CacheKey plus the image and command, so reusing a CacheKey for a
+// different command doesn't return a stale result.
+func runCacheKey(key, image string, command []string) string {
+	h := sha256.New()
+	fmt.Fprintln(h, key)
+	fmt.Fprintln(h, image)
+	for _, arg := range command {
+		fmt.Fprintln(h, arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// filesystemCacheStore is the default CacheStore, persisting each entry as
+// a file under dir.
+type filesystemCacheStore struct {
+	dir string
+}
+
+func newFilesystemCacheStore() CacheStore {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return &filesystemCacheStore{dir: filepath.Join(dir, "agentkernel")}
+}
+
+func (s *filesystemCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *filesystemCacheStore) Put(_ context.Context, key string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, key), data, 0644)
+}
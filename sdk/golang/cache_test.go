@@ -0,0 +1,137 @@
+package agentkernel
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// memCacheStore is an in-memory CacheStore for tests, so they don't touch
+// the real filesystem cache directory.
+type memCacheStore struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newMemCacheStore() *memCacheStore {
+	return &memCacheStore{entries: make(map[string][]byte)}
+}
+
+func (s *memCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.entries[key]
+	return data, ok, nil
+}
+
+func (s *memCacheStore) Put(_ context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = data
+	return nil
+}
+
+func TestRunWithCacheKeySkipsSecondCall(t *testing.T) {
+	var calls int
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		jsonOK(w, RunOutput{Output: "built"})
+	})
+	defer srv.Close()
+	client.Cache.store = newMemCacheStore()
+
+	opts := &RunOptions{CacheKey: "build-v1"}
+	out1, err := client.Run(context.Background(), []string{"make"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out2, err := client.Run(context.Background(), []string{"make"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected 1 server call, got %d", calls)
+	}
+	if out1.Output != "built" || out2.Output != "built" {
+		t.Fatalf("expected both calls to return the cached output, got %q and %q", out1.Output, out2.Output)
+	}
+}
+
+func TestRunWithCacheKeyMissesOnDifferentCommand(t *testing.T) {
+	var calls int
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		jsonOK(w, RunOutput{Output: "built"})
+	})
+	defer srv.Close()
+	client.Cache.store = newMemCacheStore()
+
+	opts := &RunOptions{CacheKey: "build-v1"}
+	if _, err := client.Run(context.Background(), []string{"make"}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Run(context.Background(), []string{"make", "test"}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected 2 server calls for different commands, got %d", calls)
+	}
+}
+
+func TestCacheSaveAndRestoreDir(t *testing.T) {
+	files := map[string]string{
+		"workspace/bin/app": "binary-content",
+	}
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/sandboxes/build-sb/manifest":
+			jsonOK(w, []ManifestEntry{{Path: "workspace/bin/app", Hash: "abc", Mode: 0755}})
+		case r.Method == http.MethodGet && r.URL.Path == "/sandboxes/build-sb/files/workspace/bin/app":
+			jsonOK(w, FileReadResponse{Content: files["workspace/bin/app"]})
+		case r.Method == http.MethodPut:
+			body := readBody(r)
+			files["workspace2/bin/app"] = body["content"].(string)
+			jsonOK(w, "ok")
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer srv.Close()
+	client.Cache.store = newMemCacheStore()
+
+	session := &SandboxSession{name: "build-sb", client: client}
+	if err := client.Cache.SaveDir(context.Background(), session, "build-v1", "workspace"); err != nil {
+		t.Fatal(err)
+	}
+
+	hit, err := client.Cache.RestoreDir(context.Background(), session, "build-v1", "workspace2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hit {
+		t.Fatal("expected a cache hit")
+	}
+	if files["workspace2/bin/app"] != "binary-content" {
+		t.Fatalf("expected restored file content, got %q", files["workspace2/bin/app"])
+	}
+}
+
+func TestCacheRestoreDirMiss(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request on cache miss: %s %s", r.Method, r.URL.Path)
+	})
+	defer srv.Close()
+	client.Cache.store = newMemCacheStore()
+
+	session := &SandboxSession{name: "build-sb", client: client}
+	hit, err := client.Cache.RestoreDir(context.Background(), session, "never-saved", "/workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hit {
+		t.Fatal("expected a cache miss")
+	}
+}
@@ -44,6 +44,28 @@ func IsServerError(err error) bool {
 	return false
 }
 
+// BuildTimeoutError reports that RunProgram's build, vet, or test step did
+// not finish within BuildTimeout. These steps report their output only
+// when they complete, so Output is empty when the timeout fires.
+type BuildTimeoutError struct {
+	Output string
+}
+
+func (e *BuildTimeoutError) Error() string {
+	return fmt.Sprintf("agentkernel: build step timed out (output so far: %q)", e.Output)
+}
+
+// RunTimeoutError reports that RunProgram's run step did not finish within
+// RunTimeout. Output holds whatever the program had produced, which may be
+// empty if the timeout fired before any output.
+type RunTimeoutError struct {
+	Output string
+}
+
+func (e *RunTimeoutError) Error() string {
+	return fmt.Sprintf("agentkernel: run step timed out (output so far: %q)", e.Output)
+}
+
 func errorFromStatus(status int, message string) *Error {
 	if message == "" {
 		switch {
@@ -0,0 +1,349 @@
+package agentkernel
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ReadFile reads a file from a sandbox.
+func (c *Client) ReadFile(ctx context.Context, name, path string) (*FileReadResponse, error) {
+	var result FileReadResponse
+	err := c.request(ctx, http.MethodGet, "/sandboxes/"+name+"/files/"+path, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WriteFile writes a file to a sandbox. encoding is typically "" (plain
+// text content) or "base64".
+func (c *Client) WriteFile(ctx context.Context, name, path, content, encoding string) error {
+	body := fileWriteRequest{Content: content, Encoding: encoding}
+	var result string
+	return c.request(ctx, http.MethodPut, "/sandboxes/"+name+"/files/"+path, body, &result)
+}
+
+// DeleteFile deletes a file from a sandbox.
+func (c *Client) DeleteFile(ctx context.Context, name, path string) error {
+	var result string
+	return c.request(ctx, http.MethodDelete, "/sandboxes/"+name+"/files/"+path, nil, &result)
+}
+
+// GetSandboxLogs returns audit log entries for a sandbox.
+func (c *Client) GetSandboxLogs(ctx context.Context, name string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := c.request(ctx, http.MethodGet, "/sandboxes/"+name+"/logs", nil, &result)
+	return result, err
+}
+
+// GetManifest returns the content hash of every file under path in a
+// sandbox, for diffing against a local directory. See SandboxSession.Sync.
+func (c *Client) GetManifest(ctx context.Context, name, path string) ([]ManifestEntry, error) {
+	var result []ManifestEntry
+	query := "?path=" + url.QueryEscape(path)
+	err := c.request(ctx, http.MethodGet, "/sandboxes/"+name+"/manifest"+query, nil, &result)
+	return result, err
+}
+
+// uploadArchive POSTs a tar.gz stream to /sandboxes/{name}/files, which
+// extracts it into the sandbox's filesystem. Like ExecInteractive, this
+// dials the first pool candidate directly rather than going through
+// Client.request's retry loop: the archive is read from once as it's
+// streamed, so a failed attempt can't simply be replayed against another
+// endpoint.
+func (c *Client) uploadArchive(ctx context.Context, name string, archive io.Reader) error {
+	candidates := c.pool.candidates()
+	if len(candidates) == 0 {
+		return fmt.Errorf("agentkernel: no endpoints configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		candidates[0].baseURL+"/sandboxes/"+name+"/files", archive)
+	if err != nil {
+		return fmt.Errorf("agentkernel: create request: %w", err)
+	}
+	c.applyHeaders(req)
+	req.Header.Set("Content-Type", "application/gzip")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("agentkernel: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return c.handleErrorResponse(resp)
+	}
+	return nil
+}
+
+// PutFile uploads a single file to remotePath in the sandbox, with mode
+// preserved in the archive entry.
+func (s *SandboxSession) PutFile(ctx context.Context, remotePath string, r io.Reader, mode os.FileMode) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("agentkernel: read file contents: %w", err)
+	}
+
+	archive, err := buildArchive([]archiveFile{{path: remotePath, mode: mode, content: content}})
+	if err != nil {
+		return err
+	}
+	return s.client.uploadArchive(ctx, s.name, archive)
+}
+
+// GetFile downloads a single file from remotePath in the sandbox. The
+// caller must close the returned reader.
+func (s *SandboxSession) GetFile(ctx context.Context, remotePath string) (io.ReadCloser, error) {
+	resp, err := s.client.ReadFile(ctx, s.name, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decodeFileContent(resp)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// Sync walks localDir and uploads every file that's new or changed
+// relative to the sandbox's remoteDir, determined by comparing sha256
+// content hashes against the server's manifest. With opts.Delete, remote
+// files under remoteDir that no longer exist locally are removed too.
+func (s *SandboxSession) Sync(ctx context.Context, localDir, remoteDir string, opts *SyncOptions) (*SyncResult, error) {
+	var ignore []string
+	concurrency := 4
+	del := false
+	if opts != nil {
+		if len(opts.Ignore) > 0 {
+			ignore = opts.Ignore
+		}
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+		del = opts.Delete
+	}
+
+	local, err := hashLocalDir(localDir, ignore, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.client.GetManifest(ctx, s.name, remoteDir)
+	if err != nil {
+		return nil, err
+	}
+	remoteHash := make(map[string]string, len(manifest))
+	for _, e := range manifest {
+		remoteHash[e.Path] = e.Hash
+	}
+
+	result := &SyncResult{}
+	var changed []archiveFile
+	for _, f := range local {
+		remotePath := filepath.ToSlash(filepath.Join(remoteDir, f.relPath))
+		if remoteHash[f.relPath] == f.hash {
+			result.Skipped = append(result.Skipped, f.relPath)
+			continue
+		}
+		content, err := os.ReadFile(f.absPath)
+		if err != nil {
+			return nil, fmt.Errorf("agentkernel: read %s: %w", f.absPath, err)
+		}
+		changed = append(changed, archiveFile{path: remotePath, mode: f.mode, content: content})
+		result.Uploaded = append(result.Uploaded, f.relPath)
+	}
+
+	if len(changed) > 0 {
+		archive, err := buildArchive(changed)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.client.uploadArchive(ctx, s.name, archive); err != nil {
+			return nil, err
+		}
+	}
+
+	if del {
+		localSet := make(map[string]struct{}, len(local))
+		for _, f := range local {
+			localSet[f.relPath] = struct{}{}
+		}
+		for _, e := range manifest {
+			if _, ok := localSet[e.Path]; ok {
+				continue
+			}
+			if matchesIgnore(ignore, e.Path) {
+				continue
+			}
+			remotePath := filepath.ToSlash(filepath.Join(remoteDir, e.Path))
+			if err := s.client.DeleteFile(ctx, s.name, remotePath); err != nil {
+				return nil, err
+			}
+			result.Deleted = append(result.Deleted, e.Path)
+		}
+	}
+
+	sort.Strings(result.Uploaded)
+	sort.Strings(result.Deleted)
+	sort.Strings(result.Skipped)
+	return result, nil
+}
+
+// localFile is one hashed file discovered under a Sync source directory.
+type localFile struct {
+	relPath string
+	absPath string
+	mode    os.FileMode
+	hash    string
+}
+
+// hashLocalDir walks dir and computes a sha256 hash for every file not
+// matched by ignore, using up to concurrency workers.
+func hashLocalDir(dir string, ignore []string, concurrency int) ([]localFile, error) {
+	var relPaths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchesIgnore(ignore, rel) {
+			return nil
+		}
+		relPaths = append(relPaths, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("agentkernel: walk %s: %w", dir, err)
+	}
+
+	files := make([]localFile, len(relPaths))
+	errs := make([]error, len(relPaths))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, rel := range relPaths {
+		i, rel := i, rel
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			files[i], errs[i] = hashFile(dir, rel)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func hashFile(dir, rel string) (localFile, error) {
+	abs := filepath.Join(dir, rel)
+	info, err := os.Stat(abs)
+	if err != nil {
+		return localFile{}, fmt.Errorf("agentkernel: stat %s: %w", abs, err)
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return localFile{}, fmt.Errorf("agentkernel: open %s: %w", abs, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return localFile{}, fmt.Errorf("agentkernel: hash %s: %w", abs, err)
+	}
+	return localFile{
+		relPath: rel,
+		absPath: abs,
+		mode:    info.Mode(),
+		hash:    hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// matchesIgnore reports whether rel matches any gitignore-style glob in
+// patterns, tried against both the full relative path and its base name.
+func matchesIgnore(patterns []string, rel string) bool {
+	for _, pat := range patterns {
+		if ok, _ := filepath.Match(pat, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pat, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveFile is one entry to be written into a tar.gz upload.
+type archiveFile struct {
+	path    string
+	mode    os.FileMode
+	content []byte
+}
+
+// buildArchive writes files into a gzip-compressed tar stream, the format
+// POST /sandboxes/{name}/files expects.
+func buildArchive(files []archiveFile) (io.Reader, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name: f.path,
+			Mode: int64(f.mode.Perm()),
+			Size: int64(len(f.content)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("agentkernel: write tar header for %s: %w", f.path, err)
+		}
+		if _, err := tw.Write(f.content); err != nil {
+			return nil, fmt.Errorf("agentkernel: write tar content for %s: %w", f.path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("agentkernel: close tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("agentkernel: close gzip writer: %w", err)
+	}
+	return &buf, nil
+}
+
+// decodeFileContent returns resp.Content as raw bytes, decoding it first
+// if the server base64-encoded it.
+func decodeFileContent(resp *FileReadResponse) ([]byte, error) {
+	if resp.Encoding != "base64" {
+		return []byte(resp.Content), nil
+	}
+	content, err := base64.StdEncoding.DecodeString(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("agentkernel: decode base64 file content: %w", err)
+	}
+	return content, nil
+}
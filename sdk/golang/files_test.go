@@ -0,0 +1,200 @@
+package agentkernel
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(t *testing.T, s string) string {
+	t.Helper()
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestReadWriteDeleteFile(t *testing.T) {
+	var wrote fileWriteRequest
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Path != "/sandboxes/my-sb/files/app.py" {
+				t.Fatalf("unexpected path: %s", r.URL.Path)
+			}
+			jsonOK(w, FileReadResponse{Content: "print(1)", Encoding: "", Size: 8})
+		case http.MethodPut:
+			body := readBody(r)
+			wrote = fileWriteRequest{Content: body["content"].(string), Encoding: fmt.Sprint(body["encoding"])}
+			jsonOK(w, "ok")
+		case http.MethodDelete:
+			jsonOK(w, "ok")
+		}
+	})
+	defer srv.Close()
+
+	out, err := client.ReadFile(context.Background(), "my-sb", "app.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Content != "print(1)" {
+		t.Fatalf("expected print(1), got %q", out.Content)
+	}
+
+	if err := client.WriteFile(context.Background(), "my-sb", "app.py", "print(2)", ""); err != nil {
+		t.Fatal(err)
+	}
+	if wrote.Content != "print(2)" {
+		t.Fatalf("expected server to receive print(2), got %q", wrote.Content)
+	}
+
+	if err := client.DeleteFile(context.Background(), "my-sb", "app.py"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetManifest(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/my-sb/manifest" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("path") != "/workspace" {
+			t.Fatalf("expected path=/workspace, got %q", r.URL.Query().Get("path"))
+		}
+		jsonOK(w, []ManifestEntry{{Path: "main.go", Hash: "abc", Mode: 0644}})
+	})
+	defer srv.Close()
+
+	manifest, err := client.GetManifest(context.Background(), "my-sb", "/workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) != 1 || manifest[0].Path != "main.go" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+}
+
+func readArchive(t *testing.T, body io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatalf("expected gzip body: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	got := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar entry: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read tar content: %v", err)
+		}
+		got[hdr.Name] = string(content)
+	}
+	return got
+}
+
+func TestPutFileUploadsArchive(t *testing.T) {
+	var uploaded map[string]string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/my-sb/files" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		uploaded = readArchive(t, r.Body)
+		jsonOK(w, "ok")
+	})
+	defer srv.Close()
+
+	session := &SandboxSession{name: "my-sb", client: client}
+	err := session.PutFile(context.Background(), "workspace/main.go", strings.NewReader("package main"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploaded["workspace/main.go"] != "package main" {
+		t.Fatalf("expected uploaded file content, got %+v", uploaded)
+	}
+}
+
+func TestSyncUploadsChangedFilesAndDeletesMissing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "changed.txt"), []byte("new content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "unchanged.txt"), []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "ignored"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ignored", "skip.log"), []byte("noise"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unchangedHash := sha256Hex(t, "same")
+
+	var deleted []string
+	var uploaded map[string]string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/sandboxes/my-sb/manifest":
+			jsonOK(w, []ManifestEntry{
+				{Path: "changed.txt", Hash: "stale-hash"},
+				{Path: "unchanged.txt", Hash: unchangedHash},
+				{Path: "stale.txt", Hash: "whatever"},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes/my-sb/files":
+			uploaded = readArchive(t, r.Body)
+			jsonOK(w, "ok")
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/sandboxes/my-sb/files/"))
+			jsonOK(w, "ok")
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	session := &SandboxSession{name: "my-sb", client: client}
+	result, err := session.Sync(context.Background(), dir, "workspace", &SyncOptions{
+		Ignore: []string{"*.log"},
+		Delete: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uploaded["workspace/changed.txt"] != "new content" {
+		t.Fatalf("expected changed.txt to be uploaded, got %+v", uploaded)
+	}
+	if _, ok := uploaded["workspace/unchanged.txt"]; ok {
+		t.Fatalf("did not expect unchanged.txt to be uploaded, got %+v", uploaded)
+	}
+
+	sort.Strings(deleted)
+	if len(deleted) != 1 || deleted[0] != "workspace/stale.txt" {
+		t.Fatalf("expected stale.txt to be deleted, got %v", deleted)
+	}
+
+	if len(result.Uploaded) != 1 || result.Uploaded[0] != "changed.txt" {
+		t.Fatalf("expected changed.txt in result.Uploaded, got %v", result.Uploaded)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "unchanged.txt" {
+		t.Fatalf("expected unchanged.txt in result.Skipped, got %v", result.Skipped)
+	}
+	if len(result.Deleted) != 1 || result.Deleted[0] != "stale.txt" {
+		t.Fatalf("expected stale.txt in result.Deleted, got %v", result.Deleted)
+	}
+}
@@ -0,0 +1,143 @@
+package agentkernel
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultFleetCleanupTimeout bounds RemoveSandbox calls RunFleet issues
+// after a job's own context is canceled or has timed out, so a canceled
+// fleet still tears down its in-flight sandboxes instead of leaking them
+// with an already-expired context.
+const defaultFleetCleanupTimeout = 10 * time.Second
+
+// FleetJob is one unit of work for RunFleet: build a sandbox from Image,
+// write Files into it, and run Command.
+type FleetJob struct {
+	Image   string
+	Command []string
+	Profile SecurityProfile
+	Files   map[string]string
+}
+
+// FleetResult is the outcome of one FleetJob, reported both in RunFleet's
+// return slice and to FleetRequest.OnResult.
+type FleetResult struct {
+	Output      string
+	Error       error
+	Duration    time.Duration
+	SandboxName string
+}
+
+// FleetRequest configures RunFleet.
+type FleetRequest struct {
+	Jobs []FleetJob
+
+	// MaxParallel bounds how many jobs run at once. Default: len(Jobs),
+	// i.e. unbounded.
+	MaxParallel int
+
+	// PerJobTimeout bounds each job's sandbox creation, file writes, and
+	// command run combined. Zero means no per-job timeout beyond ctx.
+	PerJobTimeout time.Duration
+
+	// OnResult, if set, is called as each job finishes, from whichever
+	// worker goroutine ran it. It must not block RunFleet's other
+	// in-flight workers for long.
+	OnResult func(idx int, result FleetResult)
+}
+
+// RunFleet fans a batch of jobs out across many sandboxes with bounded
+// worker-pool parallelism — the matrix/fan-out pattern CI systems like
+// Drone/Woodpecker use for pipeline steps, one step beyond the
+// single-sandbox batching of POST /batch/run (see BatchRunResponse).
+//
+// Canceling ctx stops any job that hasn't started yet and cancels the
+// in-flight ones, but RunFleet still removes their sandboxes (using a
+// short-lived context of its own, since ctx is no longer usable for that)
+// before returning. The returned slice always has one FleetResult per job,
+// in Jobs order, whether or not ctx was canceled.
+func (c *Client) RunFleet(ctx context.Context, req FleetRequest) ([]FleetResult, error) {
+	results := make([]FleetResult, len(req.Jobs))
+	if len(req.Jobs) == 0 {
+		return results, nil
+	}
+
+	maxParallel := req.MaxParallel
+	if maxParallel <= 0 {
+		maxParallel = len(req.Jobs)
+	}
+	sem := make(chan struct{}, maxParallel)
+
+	var wg sync.WaitGroup
+	for i, job := range req.Jobs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = FleetResult{Error: ctx.Err()}
+			if req.OnResult != nil {
+				req.OnResult(i, results[i])
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, job FleetJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := c.runFleetJob(ctx, i, job, req.PerJobTimeout)
+			results[i] = result
+			if req.OnResult != nil {
+				req.OnResult(i, result)
+			}
+		}(i, job)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func (c *Client) runFleetJob(ctx context.Context, idx int, job FleetJob, perJobTimeout time.Duration) FleetResult {
+	start := time.Now()
+	sandboxName := fmt.Sprintf("agentkernel-fleet-%d-%x", idx, rand.Int63())
+
+	jobCtx := ctx
+	if perJobTimeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, perJobTimeout)
+		defer cancel()
+	}
+
+	if _, err := c.CreateSandbox(jobCtx, sandboxName, &CreateSandboxOptions{Image: job.Image, Profile: job.Profile}); err != nil {
+		return FleetResult{Error: err, Duration: time.Since(start), SandboxName: sandboxName}
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), defaultFleetCleanupTimeout)
+		defer cancel()
+		c.RemoveSandbox(cleanupCtx, sandboxName) //nolint:errcheck
+	}()
+
+	session := &SandboxSession{name: sandboxName, client: c}
+	for path, content := range job.Files {
+		if err := session.WriteFile(jobCtx, path, content, ""); err != nil {
+			return FleetResult{
+				Error:       fmt.Errorf("agentkernel: write %s: %w", path, err),
+				Duration:    time.Since(start),
+				SandboxName: sandboxName,
+			}
+		}
+	}
+
+	output, err := session.Run(jobCtx, job.Command)
+	result := FleetResult{Duration: time.Since(start), SandboxName: sandboxName}
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Output = output.Output
+	return result
+}
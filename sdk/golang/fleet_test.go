@@ -0,0 +1,149 @@
+package agentkernel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunFleetHappyPath(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			jsonOK(w, map[string]string{"name": "box", "status": "running", "backend": "docker"})
+		case r.Method == http.MethodDelete:
+			jsonOK(w, "removed")
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			jsonOK(w, map[string]string{"output": "done"})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	var onResultCalls int32
+	req := FleetRequest{
+		Jobs: []FleetJob{
+			{Image: "alpine", Command: []string{"echo", "1"}},
+			{Image: "alpine", Command: []string{"echo", "2"}},
+			{Image: "alpine", Command: []string{"echo", "3"}},
+		},
+		MaxParallel: 2,
+		OnResult: func(idx int, result FleetResult) {
+			atomic.AddInt32(&onResultCalls, 1)
+		},
+	}
+
+	results, err := client.RunFleet(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Error != nil {
+			t.Fatalf("job %d: unexpected error %v", i, r.Error)
+		}
+		if r.Output != "done" {
+			t.Fatalf("job %d: expected output %q, got %q", i, "done", r.Output)
+		}
+		if r.SandboxName == "" {
+			t.Fatalf("job %d: expected a sandbox name", i)
+		}
+	}
+	if onResultCalls != 3 {
+		t.Fatalf("expected OnResult called 3 times, got %d", onResultCalls)
+	}
+}
+
+func TestRunFleetRespectsMaxParallel(t *testing.T) {
+	var inFlight, maxSeen int32
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			jsonOK(w, map[string]string{"name": "box", "status": "running", "backend": "docker"})
+		case r.Method == http.MethodDelete:
+			jsonOK(w, "removed")
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				old := atomic.LoadInt32(&maxSeen)
+				if n <= old || atomic.CompareAndSwapInt32(&maxSeen, old, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			jsonOK(w, map[string]string{"output": "done"})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	jobs := make([]FleetJob, 6)
+	for i := range jobs {
+		jobs[i] = FleetJob{Image: "alpine", Command: []string{"sleep"}}
+	}
+	_, err := client.RunFleet(context.Background(), FleetRequest{Jobs: jobs, MaxParallel: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxSeen > 2 {
+		t.Fatalf("expected at most 2 jobs in flight, saw %d", maxSeen)
+	}
+}
+
+func TestRunFleetCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var removeCalled sync.WaitGroup
+	removeCalled.Add(1)
+	var once sync.Once
+
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			jsonOK(w, map[string]string{"name": "box", "status": "running", "backend": "docker"})
+		case r.Method == http.MethodDelete:
+			once.Do(removeCalled.Done)
+			jsonOK(w, "removed")
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			cancel()
+			<-ctx.Done()
+			jsonOK(w, map[string]string{"output": "done"})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	jobs := []FleetJob{
+		{Image: "alpine", Command: []string{"sleep"}},
+		{Image: "alpine", Command: []string{"sleep"}},
+	}
+	results, err := client.RunFleet(ctx, FleetRequest{Jobs: jobs, MaxParallel: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	// RunFleet only returns once every worker (including its cleanup) has
+	// finished, so the in-flight job's sandbox must already be removed —
+	// Wait returning at all (it would otherwise block forever) is the
+	// assertion.
+	waited := make(chan struct{})
+	go func() { removeCalled.Wait(); close(waited) }()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("expected an in-flight sandbox to be removed after cancellation")
+	}
+}
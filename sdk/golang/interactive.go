@@ -0,0 +1,199 @@
+package agentkernel
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// InteractiveOptions configures an interactive exec session.
+type InteractiveOptions struct {
+	Image   string
+	Profile SecurityProfile
+	// Cols and Rows set the initial pseudo-terminal size, if the sandbox
+	// image supports one.
+	Cols int
+	Rows int
+}
+
+// InteractiveSession is a live, bidirectional exec session inside a
+// sandbox. Unlike ExecInSandbox, the process stays alive across multiple
+// inputs, so REPL-style workflows (shells, Python interpreters) don't lose
+// state between calls.
+type InteractiveSession struct {
+	// Stdin streams bytes to the sandboxed process's standard input.
+	Stdin io.WriteCloser
+	// Stdout delivers stdout, stderr, and exit events as they arrive.
+	// The channel is closed when the session ends.
+	Stdout <-chan RawStreamEvent
+
+	body io.WriteCloser
+}
+
+// Resize notifies the sandbox's pseudo-terminal of a terminal size change.
+func (s *InteractiveSession) Resize(cols, rows int) error {
+	return writeExecFrame(s.body, execFrame{Type: "resize", Cols: cols, Rows: rows})
+}
+
+// Signal sends a named signal (e.g. "SIGINT", "SIGTERM") to the running process.
+func (s *InteractiveSession) Signal(sig string) error {
+	return writeExecFrame(s.body, execFrame{Type: "signal", Signal: sig})
+}
+
+// Close half-closes the session by closing stdin. The sandboxed process
+// typically exits shortly after (e.g. a shell reading EOF), and any output
+// it still produces, including a final "exit" event, keeps arriving on
+// Stdout until that channel closes. Close does not itself wait for that to
+// happen — drain Stdout for that.
+func (s *InteractiveSession) Close() error {
+	return s.Stdin.Close()
+}
+
+// execFrame is one line of the newline-delimited JSON protocol multiplexing
+// stdin, resize, and signal control messages over the chunked request body
+// of POST /sandboxes/{name}/exec/stream.
+type execFrame struct {
+	Type    string   `json:"type"`
+	Command []string `json:"command,omitempty"`
+	Image   string   `json:"image,omitempty"`
+	Profile string   `json:"profile,omitempty"`
+	Data    string   `json:"data,omitempty"` // base64-encoded stdin bytes
+	Cols    int      `json:"cols,omitempty"`
+	Rows    int      `json:"rows,omitempty"`
+	Signal  string   `json:"signal,omitempty"`
+}
+
+func writeExecFrame(w io.Writer, f execFrame) error {
+	line, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	_, err = w.Write(line)
+	return err
+}
+
+// stdinWriter adapts io.Writer calls into base64-encoded "stdin" frames so
+// arbitrary binary input can ride the newline-delimited JSON protocol.
+type stdinWriter struct {
+	w io.Writer
+}
+
+func (s *stdinWriter) Write(p []byte) (int, error) {
+	if err := writeExecFrame(s.w, execFrame{Type: "stdin", Data: base64.StdEncoding.EncodeToString(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *stdinWriter) Close() error {
+	if err := writeExecFrame(s.w, execFrame{Type: "close"}); err != nil {
+		return err
+	}
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// ExecInteractive starts a bidirectional exec session in an existing
+// sandbox. The wire protocol is newline-delimited JSON over a chunked
+// request body (stdin/resize/signal frames) and a chunked response body
+// (stdout/stderr/exit frames), so no WebSocket dependency is required.
+func (c *Client) ExecInteractive(ctx context.Context, name string, command []string, opts *InteractiveOptions) (*InteractiveSession, error) {
+	candidates := c.pool.candidates()
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("agentkernel: no endpoints configured")
+	}
+	ctx = withRequestInfo(ctx, name, command)
+
+	pr, pw := io.Pipe()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		candidates[0].baseURL+"/sandboxes/"+name+"/exec/stream", pr)
+	if err != nil {
+		return nil, fmt.Errorf("agentkernel: create request: %w", err)
+	}
+	c.applyHeaders(req)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	start := execFrame{Type: "start", Command: command}
+	if opts != nil {
+		start.Image = opts.Image
+		start.Profile = string(opts.Profile)
+		start.Cols = opts.Cols
+		start.Rows = opts.Rows
+	}
+	// The pipe has no buffer, so the start frame can only be written once
+	// the Transport begins reading the request body — write it from a
+	// goroutine so it doesn't deadlock with the Do call below.
+	go writeExecFrame(pw, start) //nolint:errcheck
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		pw.Close()
+		return nil, fmt.Errorf("agentkernel: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		pw.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	stdout := make(chan RawStreamEvent)
+	go func() {
+		defer close(stdout)
+		defer resp.Body.Close()
+		for event := range parseNDJSON(resp.Body) {
+			stdout <- event
+		}
+	}()
+
+	return &InteractiveSession{
+		Stdin:  &stdinWriter{w: pw},
+		Stdout: stdout,
+		body:   pw,
+	}, nil
+}
+
+// parseNDJSON reads newline-delimited JSON objects from r and sends them on
+// the returned channel as RawStreamEvent, keyed by each object's "type" field.
+// The channel is closed when the stream ends.
+//
+// This reads with a bufio.Reader line loop rather than bufio.Scanner, so a
+// single line isn't bounded by Scanner's 64KB token limit — a real risk
+// here, since a single base64 "data" frame (net.go's tunneled TCP payload,
+// or a large chunk of interactive stdout) routinely exceeds that.
+func parseNDJSON(r io.Reader) <-chan RawStreamEvent {
+	ch := make(chan RawStreamEvent)
+	go func() {
+		defer close(ch)
+		reader := bufio.NewReader(r)
+		for {
+			line, readErr := reader.ReadString('\n')
+			line = strings.TrimRight(line, "\r\n")
+			if line != "" {
+				var data map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &data); err != nil {
+					ch <- RawStreamEvent{Type: "error", Data: map[string]interface{}{"raw": line}}
+				} else {
+					typ, _ := data["type"].(string)
+					if typ == "" {
+						typ = "message"
+					}
+					ch <- RawStreamEvent{Type: typ, Data: data}
+				}
+			}
+			if readErr != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,77 @@
+package agentkernel
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExecInteractiveRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sandboxes/repl/exec/stream") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		// Writing a response before the request body is fully read requires
+		// full duplex mode on net/http/httptest's HTTP/1.1 server, or the
+		// server will try to drain the (still-open) body before flushing
+		// headers and deadlock against the still-writing client.
+		if err := http.NewResponseController(w).EnableFullDuplex(); err != nil {
+			t.Fatal(err)
+		}
+		flusher := w.(http.Flusher)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.Contains(line, `"type":"start"`) {
+				w.Write([]byte(`{"type":"stdout","content":">>> "}` + "\n"))
+				flusher.Flush()
+			}
+			if strings.Contains(line, `"type":"close"`) {
+				w.Write([]byte(`{"type":"exit","exit_code":0}` + "\n"))
+				flusher.Flush()
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+	defer client.Close()
+
+	session, err := client.ExecInteractive(context.Background(), "repl", []string{"python3"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-session.Stdout
+	if first.Type != "stdout" {
+		t.Fatalf("expected stdout event, got %+v", first)
+	}
+
+	if err := session.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	last := <-session.Stdout
+	if last.Type != "exit" {
+		t.Fatalf("expected exit event, got %+v", last)
+	}
+}
+
+func TestParseNDJSONHandlesLinesOverScannerLimit(t *testing.T) {
+	big := strings.Repeat("x", 100*1024) // bigger than bufio.Scanner's 64KB default
+	ndjson := `{"type":"stdout","content":"` + big + `"}` + "\n"
+
+	events := parseNDJSON(strings.NewReader(ndjson))
+	ev, ok := <-events
+	if !ok {
+		t.Fatal("expected one event")
+	}
+	content, _ := ev.Data["content"].(string)
+	if len(content) != len(big) {
+		t.Fatalf("expected content of length %d, got %d", len(big), len(content))
+	}
+}
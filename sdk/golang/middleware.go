@@ -0,0 +1,244 @@
+package agentkernel
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoundTripperMiddleware wraps an http.RoundTripper to add cross-cutting
+// behavior — compression, logging, tracing, rate limiting — to every
+// request the client makes. Because Client.request and RunStream both call
+// httpClient.Do under the hood, middleware installed via Options.Middleware
+// applies uniformly to unary calls and streaming ones alike.
+//
+// Middleware is applied in the order given: the first entry sees the
+// request first and the response last, like a typical handler chain.
+type RoundTripperMiddleware func(http.RoundTripper) http.RoundTripper
+
+// roundTripperFunc adapts a function to the http.RoundTripper interface.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chainMiddleware wraps rt with each middleware in mw, in the order given,
+// so mw[0] is the outermost round tripper.
+func chainMiddleware(rt http.RoundTripper, mw []RoundTripperMiddleware) http.RoundTripper {
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+	return rt
+}
+
+// WithGzip requests gzip-compressed responses and transparently decodes
+// them, including the chunked bodies RunStream and ExecInteractive read
+// from as they arrive.
+func WithGzip() RoundTripperMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Accept-Encoding", "gzip")
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if resp.Header.Get("Content-Encoding") != "gzip" {
+				return resp, nil
+			}
+			gz, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				resp.Body.Close()
+				return nil, fmt.Errorf("agentkernel: decode gzip response: %w", err)
+			}
+			resp.Body = &gzipBody{gz: gz, orig: resp.Body}
+			resp.Header.Del("Content-Encoding")
+			resp.Header.Del("Content-Length")
+			resp.ContentLength = -1
+			return resp, nil
+		})
+	}
+}
+
+// gzipBody lets a gzip.Reader satisfy io.ReadCloser while still closing the
+// underlying response body it reads from.
+type gzipBody struct {
+	gz   *gzip.Reader
+	orig interface{ Close() error }
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) { return b.gz.Read(p) }
+
+func (b *gzipBody) Close() error {
+	err := b.gz.Close()
+	if origErr := b.orig.Close(); err == nil {
+		err = origErr
+	}
+	return err
+}
+
+// Logger is the minimal logging interface WithRequestLogging needs. Most
+// structured loggers can be adapted to it with a one-line wrapper; LoggerFunc
+// covers the common case of a bare function.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LoggerFunc adapts a function to the Logger interface.
+type LoggerFunc func(format string, args ...interface{})
+
+// Printf calls f.
+func (f LoggerFunc) Printf(format string, args ...interface{}) { f(format, args...) }
+
+// WithRequestLogging logs the method, path, status, and duration of every
+// request the client makes.
+func WithRequestLogging(logger Logger) RoundTripperMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			elapsed := time.Since(start)
+			if err != nil {
+				logger.Printf("agentkernel: %s %s failed after %s: %v", req.Method, req.URL.Path, elapsed, err)
+				return nil, err
+			}
+			logger.Printf("agentkernel: %s %s -> %d (%s)", req.Method, req.URL.Path, resp.StatusCode, elapsed)
+			return resp, nil
+		})
+	}
+}
+
+// Attribute is a single key/value pair attached to a Span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is the minimal span interface WithOpenTelemetry needs from a tracing
+// library. It is deliberately small so this package stays dependency-free;
+// wrap your tracer's native span type (e.g. go.opentelemetry.io/otel's
+// trace.Span) in an adapter that satisfies it.
+type Span interface {
+	// TraceID and SpanID are hex-encoded identifiers used to build the
+	// W3C traceparent header injected into the outgoing request.
+	TraceID() string
+	SpanID() string
+	SetAttributes(attrs ...Attribute)
+	End()
+}
+
+// Tracer starts a Span for an outgoing request.
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// WithOpenTelemetry starts a span per request via tracer, tagging it with
+// agentkernel.sandbox and agentkernel.command attributes (populated for
+// Run, RunStream, ExecInSandbox, ExecStream, ExecInteractive, and
+// SandboxSession's Dial and Listen; empty otherwise) and
+// injecting a W3C traceparent header so the span can be correlated with the
+// server's own tracing.
+func WithOpenTelemetry(tracer Tracer) RoundTripperMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), "agentkernel."+req.Method+" "+req.URL.Path)
+			defer span.End()
+
+			sandbox, command := requestInfoFromContext(ctx)
+			span.SetAttributes(
+				Attribute{Key: "agentkernel.sandbox", Value: sandbox},
+				Attribute{Key: "agentkernel.command", Value: strings.Join(command, " ")},
+			)
+
+			req = req.WithContext(ctx)
+			req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", span.TraceID(), span.SpanID()))
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.SetAttributes(Attribute{Key: "error", Value: err.Error()})
+			}
+			return resp, err
+		})
+	}
+}
+
+// requestInfoKey is the context key Run, RunStream, ExecInSandbox,
+// ExecStream, ExecInteractive, Dial, and Listen use to attach the sandbox
+// name and command they're about
+// to issue, so middleware like WithOpenTelemetry can tag spans without
+// every call site threading the values through explicitly.
+type requestInfoKey struct{}
+
+type requestInfo struct {
+	sandbox string
+	command []string
+}
+
+func withRequestInfo(ctx context.Context, sandbox string, command []string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, requestInfo{sandbox: sandbox, command: command})
+}
+
+func requestInfoFromContext(ctx context.Context) (sandbox string, command []string) {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info.sandbox, info.command
+}
+
+// WithRateLimit throttles outgoing requests to at most rps per second using
+// a token bucket with a burst of rps (at least 1), blocking until a token is available or
+// the request's context is canceled.
+func WithRateLimit(rps float64) RoundTripperMiddleware {
+	bucket := newTokenBucket(rps)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := bucket.wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// tokenBucket is a simple token-bucket rate limiter. It exists so
+// WithRateLimit doesn't need golang.org/x/time/rate, keeping this package
+// dependency-free.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens added per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rps float64) *tokenBucket {
+	burst := math.Max(rps, 1)
+	return &tokenBucket{rate: rps, capacity: burst, tokens: burst, last: time.Now()}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package agentkernel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithGzipDecodesResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected Accept-Encoding: gzip, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		json.NewEncoder(gz).Encode(map[string]interface{}{"success": true, "data": "ok"})
+		gz.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL, Middleware: []RoundTripperMiddleware{WithGzip()}})
+	defer client.Close()
+
+	result, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected ok, got %q", result)
+	}
+}
+
+func TestWithRequestLoggingRecordsOutcome(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonOK(w, "ok")
+	}))
+	defer srv.Close()
+
+	var lines []string
+	logger := LoggerFunc(func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	})
+
+	client := New(&Options{BaseURL: srv.URL, Middleware: []RoundTripperMiddleware{WithRequestLogging(logger)}})
+	defer client.Close()
+
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 log line, got %v", lines)
+	}
+	if !bytes.Contains([]byte(lines[0]), []byte("GET /health -> 200")) {
+		t.Fatalf("unexpected log line: %q", lines[0])
+	}
+}
+
+func TestWithOpenTelemetryInjectsTraceparent(t *testing.T) {
+	var gotHeader, gotSandbox, gotCommand string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("traceparent")
+		jsonOK(w, RunOutput{Output: "ok"})
+	}))
+	defer srv.Close()
+
+	tracer := fakeTracer{onStart: func(sandbox string, command []string) {
+		gotSandbox = sandbox
+		gotCommand = fmt.Sprint(command)
+	}}
+	client := New(&Options{BaseURL: srv.URL, Middleware: []RoundTripperMiddleware{WithOpenTelemetry(tracer)}})
+	defer client.Close()
+
+	if _, err := client.Run(context.Background(), []string{"echo", "hi"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if gotHeader != "00-trace123-span456-01" {
+		t.Fatalf("unexpected traceparent: %q", gotHeader)
+	}
+	if gotSandbox != "" {
+		t.Fatalf("expected empty sandbox for Run, got %q", gotSandbox)
+	}
+	if gotCommand != "[echo hi]" {
+		t.Fatalf("expected command attribute, got %q", gotCommand)
+	}
+}
+
+func TestWithRateLimitThrottles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonOK(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL, Middleware: []RoundTripperMiddleware{WithRateLimit(5)}})
+	defer client.Close()
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := client.Health(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected 10 requests at 5rps to take at least 1s, took %s", elapsed)
+	}
+}
+
+// fakeTracer is a minimal Tracer for tests.
+type fakeTracer struct {
+	onStart func(sandbox string, command []string)
+}
+
+func (f fakeTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	sandbox, command := requestInfoFromContext(ctx)
+	if f.onStart != nil {
+		f.onStart(sandbox, command)
+	}
+	return ctx, fakeSpan{}
+}
+
+type fakeSpan struct{}
+
+func (fakeSpan) TraceID() string                  { return "trace123" }
+func (fakeSpan) SpanID() string                   { return "span456" }
+func (fakeSpan) SetAttributes(attrs ...Attribute) {}
+func (fakeSpan) End()                             {}
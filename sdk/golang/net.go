@@ -0,0 +1,479 @@
+package agentkernel
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// netFrame is one line of the newline-delimited JSON protocol multiplexing
+// TCP tunnel control and data over the chunked request/response bodies of
+// POST /sandboxes/{name}/net/dial and /net/listen. ConnID distinguishes
+// accepted connections multiplexed over a single /net/listen stream; it is
+// unused (and omitted) on /net/dial, which tunnels exactly one connection.
+type netFrame struct {
+	Type    string `json:"type"`
+	Network string `json:"network,omitempty"`
+	Addr    string `json:"addr,omitempty"`
+	ConnID  string `json:"conn_id,omitempty"`
+	Data    string `json:"data,omitempty"` // base64-encoded payload bytes
+	Message string `json:"message,omitempty"`
+}
+
+func writeNetFrame(w io.Writer, f netFrame) error {
+	line, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	_, err = w.Write(line)
+	return err
+}
+
+// tunnelAddr is the net.Addr implementation for tunneled connections: the
+// server reports network and addr, but there is no local socket to
+// introspect on the client side.
+type tunnelAddr struct {
+	network, addr string
+}
+
+func (a tunnelAddr) Network() string { return a.network }
+func (a tunnelAddr) String() string  { return a.addr }
+
+// pipeDeadline implements the deadline half of net.Conn for a connection
+// with no underlying file descriptor to set deadlines on: a cancel channel
+// closed by a time.AfterFunc timer when the deadline elapses, so a blocked
+// Read or Write can select on it and unblock with os.ErrDeadlineExceeded.
+// Dial and Listen each keep one pipeDeadline per direction (read, write).
+type pipeDeadline struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel chan struct{}
+}
+
+func makePipeDeadline() pipeDeadline {
+	return pipeDeadline{cancel: make(chan struct{})}
+}
+
+// set arms or disarms the deadline. A zero Time disarms it.
+func (d *pipeDeadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancel // the timer already fired; wait for its close to land
+	}
+	d.timer = nil
+
+	closed := isClosedChan(d.cancel)
+	switch {
+	case t.IsZero():
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+	case time.Until(t) > 0:
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+		cancel := d.cancel
+		d.timer = time.AfterFunc(time.Until(t), func() { close(cancel) })
+	default:
+		if !closed {
+			close(d.cancel)
+		}
+	}
+}
+
+// wait returns the channel that closes when the deadline elapses.
+func (d *pipeDeadline) wait() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
+
+func isClosedChan(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// tunnelConn is a net.Conn tunneled over the agentkernel server into a
+// sandbox's network namespace, gVisor gonet-style: the payload rides
+// base64 "data" frames inside the same ndjson framing ExecInteractive uses
+// for stdin/stdout, and deadlines are enforced client-side since the
+// underlying transport is an HTTP request/response pair, not a socket.
+type tunnelConn struct {
+	local, remote tunnelAddr
+	connID        string // set for connections accepted by a tunnelListener; "" for Dial
+
+	body io.Writer
+
+	readBuf   []byte
+	readCh    chan []byte
+	remoteEOF chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	closeFn   func() error
+
+	readDeadline  pipeDeadline
+	writeDeadline pipeDeadline
+}
+
+func newTunnelConn(local, remote tunnelAddr, connID string, body io.Writer, closeFn func() error) *tunnelConn {
+	return &tunnelConn{
+		local:         local,
+		remote:        remote,
+		connID:        connID,
+		body:          body,
+		readCh:        make(chan []byte, 16),
+		remoteEOF:     make(chan struct{}),
+		closed:        make(chan struct{}),
+		closeFn:       closeFn,
+		readDeadline:  makePipeDeadline(),
+		writeDeadline: makePipeDeadline(),
+	}
+}
+
+// deliver hands a decoded "data" frame's payload to the reader. It is
+// called from Dial's own drain loop or a tunnelListener's demux loop, never
+// concurrently with itself.
+func (c *tunnelConn) deliver(p []byte) {
+	select {
+	case c.readCh <- p:
+	case <-c.closed:
+	}
+}
+
+// remoteClosed marks that the server side sent a "closed" frame or ended
+// the stream; pending and future Reads observe io.EOF once the buffered
+// data drains.
+func (c *tunnelConn) remoteClosed() {
+	select {
+	case <-c.remoteEOF:
+	default:
+		close(c.remoteEOF)
+	}
+}
+
+func (c *tunnelConn) Read(p []byte) (int, error) {
+	if len(c.readBuf) == 0 {
+		select {
+		case chunk, ok := <-c.readCh:
+			if !ok {
+				return 0, io.EOF
+			}
+			c.readBuf = chunk
+		case <-c.remoteEOF:
+			select {
+			case chunk := <-c.readCh:
+				c.readBuf = chunk
+			default:
+				return 0, io.EOF
+			}
+		case <-c.readDeadline.wait():
+			return 0, os.ErrDeadlineExceeded
+		case <-c.closed:
+			return 0, net.ErrClosed
+		}
+	}
+	n := copy(p, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+func (c *tunnelConn) Write(p []byte) (int, error) {
+	select {
+	case <-c.writeDeadline.wait():
+		return 0, os.ErrDeadlineExceeded
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	f := netFrame{Type: "data", ConnID: c.connID, Data: base64.StdEncoding.EncodeToString(p)}
+	if err := writeNetFrame(c.body, f); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *tunnelConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		if c.closeFn != nil {
+			err = c.closeFn()
+		}
+	})
+	return err
+}
+
+func (c *tunnelConn) LocalAddr() net.Addr  { return c.local }
+func (c *tunnelConn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *tunnelConn) SetDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	c.writeDeadline.set(t)
+	return nil
+}
+
+func (c *tunnelConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+func (c *tunnelConn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// Dial opens a TCP connection from inside the sandbox's network namespace
+// and tunnels it back over the agentkernel server, gonet-style: the
+// returned net.Conn's Read/Write ride base64 "data" frames over a chunked
+// POST /sandboxes/{name}/net/dial request, so ordinary net/http clients
+// and database drivers work against services running only inside the
+// sandbox.
+func (s *SandboxSession) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	c := s.client
+	candidates := c.pool.candidates()
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("agentkernel: no endpoints configured")
+	}
+	ctx = withRequestInfo(ctx, s.name, []string{"dial", network, addr})
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		candidates[0].baseURL+"/sandboxes/"+s.name+"/net/dial", pr)
+	if err != nil {
+		return nil, fmt.Errorf("agentkernel: create request: %w", err)
+	}
+	c.applyHeaders(req)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	// The pipe has no buffer, so the open frame can only be written once
+	// the Transport begins reading the request body — write it from a
+	// goroutine, matching ExecInteractive's start frame.
+	go writeNetFrame(pw, netFrame{Type: "open", Network: network, Addr: addr}) //nolint:errcheck
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		pw.Close()
+		return nil, fmt.Errorf("agentkernel: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		pw.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	events := parseNDJSON(resp.Body)
+	first, ok := <-events
+	if !ok {
+		resp.Body.Close()
+		pw.Close()
+		return nil, fmt.Errorf("agentkernel: net dial %s %s: connection closed before it opened", network, addr)
+	}
+	if first.Type == "error" {
+		message, _ := first.Data["message"].(string)
+		resp.Body.Close()
+		pw.Close()
+		return nil, fmt.Errorf("agentkernel: net dial %s %s: %s", network, addr, message)
+	}
+
+	conn := newTunnelConn(tunnelAddr{network, "sandbox:" + s.name}, tunnelAddr{network, addr}, "", pw, func() error {
+		writeNetFrame(pw, netFrame{Type: "close"}) //nolint:errcheck
+		pw.Close()
+		return resp.Body.Close()
+	})
+
+	go func() {
+		defer conn.remoteClosed()
+		for ev := range events {
+			switch ev.Type {
+			case "data":
+				if content, ok := ev.Data["data"].(string); ok {
+					if decoded, err := base64.StdEncoding.DecodeString(content); err == nil {
+						conn.deliver(decoded)
+					}
+				}
+			case "closed", "error":
+				return
+			}
+		}
+	}()
+
+	return conn, nil
+}
+
+// tunnelListener is a net.Listener whose accepted connections are
+// multiplexed, by ConnID, over a single ndjson stream opened with
+// POST /sandboxes/{name}/net/listen.
+type tunnelListener struct {
+	network, addr string
+	body          io.WriteCloser
+	respBody      io.Closer
+
+	acceptCh  chan *tunnelConn
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	conns map[string]*tunnelConn
+	err   error
+}
+
+// Listen asks the sandbox to bind network/addr and tunnels every
+// connection accepted there back over the agentkernel server to the
+// returned net.Listener's Accept, so a server running in this process can
+// be reached at addr by anything inside the sandbox — including, via
+// Dial, this same process.
+func (s *SandboxSession) Listen(ctx context.Context, network, addr string) (net.Listener, error) {
+	c := s.client
+	candidates := c.pool.candidates()
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("agentkernel: no endpoints configured")
+	}
+	ctx = withRequestInfo(ctx, s.name, []string{"listen", network, addr})
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		candidates[0].baseURL+"/sandboxes/"+s.name+"/net/listen", pr)
+	if err != nil {
+		return nil, fmt.Errorf("agentkernel: create request: %w", err)
+	}
+	c.applyHeaders(req)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	go writeNetFrame(pw, netFrame{Type: "listen", Network: network, Addr: addr}) //nolint:errcheck
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		pw.Close()
+		return nil, fmt.Errorf("agentkernel: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		pw.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	events := parseNDJSON(resp.Body)
+	first, ok := <-events
+	if !ok || first.Type == "error" {
+		message, _ := first.Data["message"].(string)
+		resp.Body.Close()
+		pw.Close()
+		if message == "" {
+			message = "connection closed before the listener was ready"
+		}
+		return nil, fmt.Errorf("agentkernel: net listen %s %s: %s", network, addr, message)
+	}
+
+	l := &tunnelListener{
+		network:  network,
+		addr:     addr,
+		body:     pw,
+		respBody: resp.Body,
+		acceptCh: make(chan *tunnelConn),
+		closed:   make(chan struct{}),
+		conns:    make(map[string]*tunnelConn),
+	}
+	go l.demux(events)
+	return l, nil
+}
+
+func (l *tunnelListener) demux(events <-chan RawStreamEvent) {
+	for ev := range events {
+		switch ev.Type {
+		case "accept":
+			connID, _ := ev.Data["conn_id"].(string)
+			remoteAddr, _ := ev.Data["addr"].(string)
+			conn := newTunnelConn(tunnelAddr{l.network, l.addr}, tunnelAddr{l.network, remoteAddr}, connID, l.body, func() error {
+				return writeNetFrame(l.body, netFrame{Type: "close", ConnID: connID})
+			})
+			l.mu.Lock()
+			l.conns[connID] = conn
+			l.mu.Unlock()
+			select {
+			case l.acceptCh <- conn:
+			case <-l.closed:
+				return
+			}
+		case "data":
+			connID, _ := ev.Data["conn_id"].(string)
+			content, _ := ev.Data["data"].(string)
+			l.mu.Lock()
+			conn := l.conns[connID]
+			l.mu.Unlock()
+			if conn == nil {
+				continue
+			}
+			if decoded, err := base64.StdEncoding.DecodeString(content); err == nil {
+				conn.deliver(decoded)
+			}
+		case "closed":
+			connID, _ := ev.Data["conn_id"].(string)
+			l.mu.Lock()
+			conn := l.conns[connID]
+			delete(l.conns, connID)
+			l.mu.Unlock()
+			if conn != nil {
+				conn.remoteClosed()
+			}
+		case "error":
+			message, _ := ev.Data["message"].(string)
+			l.fail(fmt.Errorf("agentkernel: net listen %s %s: %s", l.network, l.addr, message))
+			return
+		}
+	}
+	l.fail(fmt.Errorf("agentkernel: net listen %s %s: stream closed", l.network, l.addr))
+}
+
+func (l *tunnelListener) fail(err error) {
+	l.mu.Lock()
+	if l.err == nil {
+		l.err = err
+	}
+	l.mu.Unlock()
+	l.Close()
+}
+
+func (l *tunnelListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.acceptCh:
+		return conn, nil
+	case <-l.closed:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.err != nil {
+			return nil, l.err
+		}
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *tunnelListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		writeNetFrame(l.body, netFrame{Type: "close"}) //nolint:errcheck
+		l.body.Close()
+		err = l.respBody.Close()
+	})
+	return err
+}
+
+func (l *tunnelListener) Addr() net.Addr {
+	return tunnelAddr{l.network, l.addr}
+}
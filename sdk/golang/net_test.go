@@ -0,0 +1,175 @@
+package agentkernel
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDialRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sandboxes/box/net/dial") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := http.NewResponseController(w).EnableFullDuplex(); err != nil {
+			t.Fatal(err)
+		}
+		flusher := w.(http.Flusher)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var frame netFrame
+			if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+				t.Fatal(err)
+			}
+			switch frame.Type {
+			case "open":
+				if frame.Network != "tcp" || frame.Addr != "127.0.0.1:8080" {
+					t.Errorf("unexpected open frame: %+v", frame)
+				}
+				w.Write([]byte(`{"type":"connected"}` + "\n"))
+				flusher.Flush()
+			case "data":
+				echo := netFrame{Type: "data", Data: frame.Data}
+				line, _ := json.Marshal(echo)
+				w.Write(append(line, '\n'))
+				flusher.Flush()
+			case "close":
+				w.Write([]byte(`{"type":"closed"}` + "\n"))
+				flusher.Flush()
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+	defer client.Close()
+
+	session := &SandboxSession{name: "box", client: client}
+	conn, err := session.Dial(context.Background(), "tcp", "127.0.0.1:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := conn.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("expected echoed %q, got %q", "ping", buf)
+	}
+}
+
+func TestDialReadDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).EnableFullDuplex(); err != nil {
+			t.Fatal(err)
+		}
+		flusher := w.(http.Flusher)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var frame netFrame
+			json.Unmarshal(scanner.Bytes(), &frame)
+			if frame.Type == "open" {
+				w.Write([]byte(`{"type":"connected"}` + "\n"))
+				flusher.Flush()
+			}
+		}
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+	defer client.Close()
+
+	session := &SandboxSession{name: "box", client: client}
+	conn, err := session.Dial(context.Background(), "tcp", "127.0.0.1:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected os.ErrDeadlineExceeded, got %v", err)
+	}
+}
+
+func TestListenAcceptRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/net/listen") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := http.NewResponseController(w).EnableFullDuplex(); err != nil {
+			t.Fatal(err)
+		}
+		flusher := w.(http.Flusher)
+		scanner := bufio.NewScanner(r.Body)
+		accepted := false
+		for scanner.Scan() {
+			var frame netFrame
+			if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+				t.Fatal(err)
+			}
+			switch frame.Type {
+			case "listen":
+				w.Write([]byte(`{"type":"listening"}` + "\n"))
+				flusher.Flush()
+				if !accepted {
+					accepted = true
+					w.Write([]byte(`{"type":"accept","conn_id":"c1","addr":"10.0.0.5:9999"}` + "\n"))
+					flusher.Flush()
+				}
+			case "data":
+				echo, _ := json.Marshal(netFrame{Type: "data", ConnID: frame.ConnID, Data: frame.Data})
+				w.Write(append(echo, '\n'))
+				flusher.Flush()
+			case "close":
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+	defer client.Close()
+
+	session := &SandboxSession{name: "box", client: client}
+	l, err := session.Listen(context.Background(), "tcp", "0.0.0.0:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if conn.RemoteAddr().String() != "10.0.0.5:9999" {
+		t.Fatalf("expected remote addr 10.0.0.5:9999, got %s", conn.RemoteAddr())
+	}
+
+	if _, err := conn.Write([]byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2)
+	if _, err := conn.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hi" {
+		t.Fatalf("expected %q, got %q", "hi", buf)
+	}
+}
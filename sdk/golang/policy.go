@@ -0,0 +1,213 @@
+package agentkernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Policy is a structured, fine-grained alternative to SecurityProfile: it
+// spells out exactly which hosts, filesystem paths, and environment
+// variables a sandbox may touch, plus any syscalls to explicitly deny,
+// instead of picking from three coarse tiers. Build one with NewPolicy, or
+// start from PermissivePolicy/ModeratePolicy/RestrictivePolicy, which
+// reproduce the equivalent SecurityProfile as a Policy for callers
+// migrating one field at a time.
+//
+// Set Policy on RunOptions or CreateSandboxOptions alongside, or instead
+// of, Profile. If both are set, the server treats Policy as the
+// authoritative source and Profile as a hint for logging/metrics only.
+type Policy struct {
+	// Network lists the hosts (host, host:port, or CIDR) the sandbox may
+	// dial out to. Nil means "use the profile default"; a non-nil empty
+	// slice denies all outbound network access.
+	Network []string `json:"network"`
+
+	// Filesystem lists the paths the sandbox may read or write, in
+	// addition to its own working directory.
+	Filesystem []string `json:"filesystem,omitempty"`
+
+	// Env lists the environment variable names passed through from the
+	// host into the sandbox.
+	Env []string `json:"env,omitempty"`
+
+	// DeniedSyscalls lists syscalls to block beyond whatever the sandbox
+	// backend already blocks by default.
+	DeniedSyscalls []string `json:"denied_syscalls,omitempty"`
+}
+
+// PolicyBuilder incrementally assembles a Policy. Its methods return the
+// builder so calls can be chained; nothing is applied until Build.
+type PolicyBuilder struct {
+	policy Policy
+}
+
+// NewPolicy starts an empty PolicyBuilder.
+func NewPolicy() *PolicyBuilder {
+	return &PolicyBuilder{}
+}
+
+// AllowNetwork adds hosts (host, host:port, or CIDR) the sandbox may dial
+// out to.
+func (b *PolicyBuilder) AllowNetwork(hosts ...string) *PolicyBuilder {
+	b.policy.Network = append(b.policy.Network, hosts...)
+	return b
+}
+
+// AllowFilesystem adds paths the sandbox may read or write.
+func (b *PolicyBuilder) AllowFilesystem(paths ...string) *PolicyBuilder {
+	b.policy.Filesystem = append(b.policy.Filesystem, paths...)
+	return b
+}
+
+// AllowEnv adds environment variable names passed through into the
+// sandbox.
+func (b *PolicyBuilder) AllowEnv(keys ...string) *PolicyBuilder {
+	b.policy.Env = append(b.policy.Env, keys...)
+	return b
+}
+
+// DenySyscalls adds syscalls to block beyond the sandbox backend's
+// defaults.
+func (b *PolicyBuilder) DenySyscalls(names ...string) *PolicyBuilder {
+	b.policy.DeniedSyscalls = append(b.policy.DeniedSyscalls, names...)
+	return b
+}
+
+// Build returns the assembled Policy. The builder can keep being used
+// afterward; Build takes a snapshot rather than handing out its internal
+// state.
+func (b *PolicyBuilder) Build() *Policy {
+	p := b.policy
+	p.Network = append([]string(nil), b.policy.Network...)
+	p.Filesystem = append([]string(nil), b.policy.Filesystem...)
+	p.Env = append([]string(nil), b.policy.Env...)
+	p.DeniedSyscalls = append([]string(nil), b.policy.DeniedSyscalls...)
+	return &p
+}
+
+// PermissivePolicy returns the Policy equivalent of ProfilePermissive:
+// unrestricted network, filesystem, and environment access.
+func PermissivePolicy() *Policy {
+	return NewPolicy().AllowNetwork("*").AllowFilesystem("/").AllowEnv("*").Build()
+}
+
+// ModeratePolicy returns the Policy equivalent of ProfileModerate: network
+// access limited to common package registries, filesystem access limited
+// to the working directory, and no environment passthrough.
+func ModeratePolicy() *Policy {
+	return NewPolicy().
+		AllowNetwork("registry.npmjs.org", "pypi.org", "proxy.golang.org", "github.com").
+		AllowFilesystem(".").
+		Build()
+}
+
+// RestrictivePolicy returns the Policy equivalent of ProfileRestrictive: no
+// network access, filesystem access limited to the working directory, and
+// no environment passthrough.
+func RestrictivePolicy() *Policy {
+	p := NewPolicy().AllowFilesystem(".").Build()
+	p.Network = []string{}
+	return p
+}
+
+// PolicyError reports one problem found by Policy.Validate. Field names the
+// offending Policy field ("network", "filesystem", "env",
+// "denied_syscalls"), matching its json tag.
+type PolicyError struct {
+	Field   string
+	Value   string
+	Message string
+}
+
+func (e *PolicyError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("agentkernel: policy.%s: %s", e.Field, e.Message)
+	}
+	return fmt.Sprintf("agentkernel: policy.%s %q: %s", e.Field, e.Value, e.Message)
+}
+
+// Validate checks p for problems a server round-trip would otherwise be
+// needed to discover — empty or duplicate entries, and env keys that
+// aren't valid environment variable names — and returns them all joined
+// with errors.Join, or nil if p is well-formed.
+func (p *Policy) Validate() error {
+	var errs []error
+	errs = append(errs, validateEntries("network", p.Network, isValidHostEntry)...)
+	errs = append(errs, validateEntries("filesystem", p.Filesystem, isValidPathEntry)...)
+	errs = append(errs, validateEntries("env", p.Env, isValidEnvKey)...)
+	errs = append(errs, validateEntries("denied_syscalls", p.DeniedSyscalls, isValidSyscallName)...)
+	return errors.Join(errs...)
+}
+
+func validateEntries(field string, entries []string, valid func(string) bool) []error {
+	var errs []error
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry == "" {
+			errs = append(errs, &PolicyError{Field: field, Message: "entry must not be empty"})
+			continue
+		}
+		if seen[entry] {
+			errs = append(errs, &PolicyError{Field: field, Value: entry, Message: "duplicate entry"})
+			continue
+		}
+		seen[entry] = true
+		if !valid(entry) {
+			errs = append(errs, &PolicyError{Field: field, Value: entry, Message: "not a valid entry"})
+		}
+	}
+	return errs
+}
+
+func isValidHostEntry(s string) bool {
+	return s == "*" || !strings.ContainsAny(s, " \t\n")
+}
+
+func isValidPathEntry(s string) bool {
+	return !strings.ContainsAny(s, " \t\n")
+}
+
+func isValidEnvKey(s string) bool {
+	if s == "*" {
+		return true
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z'):
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isValidSyscallName(s string) bool {
+	for _, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// EffectivePolicy returns the server-resolved Policy actually enforced on
+// the sandbox: whatever the caller passed as CreateSandboxOptions.Policy,
+// merged with the SecurityProfile default for anything left unset.
+func (s *SandboxSession) EffectivePolicy(ctx context.Context) (*Policy, error) {
+	return s.client.getEffectivePolicy(ctx, s.name)
+}
+
+func (c *Client) getEffectivePolicy(ctx context.Context, name string) (*Policy, error) {
+	var result Policy
+	err := c.request(ctx, http.MethodGet, "/sandboxes/"+name+"/policy", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
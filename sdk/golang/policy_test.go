@@ -0,0 +1,99 @@
+package agentkernel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPolicyBuilder(t *testing.T) {
+	p := NewPolicy().
+		AllowNetwork("api.example.com").
+		AllowFilesystem("/workspace").
+		AllowEnv("PATH", "HOME").
+		DenySyscalls("ptrace").
+		Build()
+
+	want := &Policy{
+		Network:        []string{"api.example.com"},
+		Filesystem:     []string{"/workspace"},
+		Env:            []string{"PATH", "HOME"},
+		DeniedSyscalls: []string{"ptrace"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("got %+v, want %+v", p, want)
+	}
+}
+
+func TestPolicyBuilderReuse(t *testing.T) {
+	b := NewPolicy().AllowNetwork("a.example.com")
+	first := b.Build()
+	b.AllowNetwork("b.example.com")
+	second := b.Build()
+
+	if len(first.Network) != 1 {
+		t.Fatalf("Build should snapshot: first mutated to %v", first.Network)
+	}
+	if len(second.Network) != 2 {
+		t.Fatalf("expected second snapshot to include both hosts, got %v", second.Network)
+	}
+}
+
+func TestPolicyPresetsMigrateCleanly(t *testing.T) {
+	for _, preset := range []func() *Policy{PermissivePolicy, ModeratePolicy, RestrictivePolicy} {
+		p := preset()
+		if err := p.Validate(); err != nil {
+			t.Errorf("preset %+v failed validation: %v", p, err)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *Policy
+		wantErr bool
+	}{
+		{"empty policy", &Policy{}, false},
+		{"valid", NewPolicy().AllowNetwork("example.com").AllowEnv("PATH").Build(), false},
+		{"empty network entry", &Policy{Network: []string{""}}, true},
+		{"duplicate filesystem entry", &Policy{Filesystem: []string{"/tmp", "/tmp"}}, true},
+		{"invalid env key", &Policy{Env: []string{"1BAD"}}, true},
+		{"invalid syscall name", &Policy{DeniedSyscalls: []string{"PTRACE!"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var polErr *PolicyError
+				if !errors.As(err, &polErr) {
+					t.Fatalf("expected error to unwrap to *PolicyError, got %T", err)
+				}
+			}
+		})
+	}
+}
+
+func TestEffectivePolicy(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/sandboxes/tmp/policy" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		jsonOK(w, Policy{Network: []string{"pypi.org"}, Filesystem: []string{"."}})
+	})
+	defer srv.Close()
+
+	session := &SandboxSession{name: "tmp", client: client}
+	policy, err := session.EffectivePolicy(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.Network) != 1 || policy.Network[0] != "pypi.org" {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+}
@@ -0,0 +1,185 @@
+package agentkernel
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHealthcheckInterval = 10 * time.Second
+	defaultHealthcheckTimeout  = 2 * time.Second
+)
+
+// EndpointStatus describes the observed health of one server in the pool.
+type EndpointStatus struct {
+	BaseURL string
+	Alive   bool
+	// LastChecked is zero if healthchecking is disabled or no check has run yet.
+	LastChecked time.Time
+	// LastError is the error from the most recent failed healthcheck, if any.
+	LastError error
+}
+
+// endpoint tracks the mutable health state of one configured BaseURL.
+type endpoint struct {
+	baseURL string
+
+	mu          sync.Mutex
+	alive       bool
+	lastChecked time.Time
+	lastErr     error
+}
+
+func (e *endpoint) status() EndpointStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return EndpointStatus{
+		BaseURL:     e.baseURL,
+		Alive:       e.alive,
+		LastChecked: e.lastChecked,
+		LastError:   e.lastErr,
+	}
+}
+
+func (e *endpoint) setAlive(alive bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.alive = alive
+	e.lastChecked = time.Now()
+	e.lastErr = err
+}
+
+func (e *endpoint) isAlive() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.alive
+}
+
+// pool round-robins across a set of endpoints, skipping ones marked dead.
+type pool struct {
+	endpoints []*endpoint
+
+	mu   sync.Mutex
+	next int
+
+	healthcheckEnabled  bool
+	healthcheckInterval time.Duration
+	healthcheckTimeout  time.Duration
+	httpClient          *http.Client
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func newPool(baseURLs []string, healthcheckEnabled bool, interval, timeout time.Duration, httpClient *http.Client) *pool {
+	if interval <= 0 {
+		interval = defaultHealthcheckInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultHealthcheckTimeout
+	}
+	endpoints := make([]*endpoint, len(baseURLs))
+	for i, u := range baseURLs {
+		endpoints[i] = &endpoint{baseURL: u, alive: true}
+	}
+	p := &pool{
+		endpoints:           endpoints,
+		healthcheckEnabled:  healthcheckEnabled,
+		healthcheckInterval: interval,
+		healthcheckTimeout:  timeout,
+		httpClient:          httpClient,
+	}
+	if healthcheckEnabled {
+		p.stopCh = make(chan struct{})
+		p.doneCh = make(chan struct{})
+		go p.healthcheckLoop()
+	}
+	return p
+}
+
+// candidates returns the endpoints in round-robin order starting from the
+// next index, with healthy endpoints first so a dead node is only used
+// when nothing else is available.
+func (p *pool) candidates() []*endpoint {
+	p.mu.Lock()
+	start := p.next
+	p.next = (p.next + 1) % len(p.endpoints)
+	p.mu.Unlock()
+
+	ordered := make([]*endpoint, 0, len(p.endpoints))
+	for i := 0; i < len(p.endpoints); i++ {
+		ordered = append(ordered, p.endpoints[(start+i)%len(p.endpoints)])
+	}
+
+	healthy := make([]*endpoint, 0, len(ordered))
+	dead := make([]*endpoint, 0, len(ordered))
+	for _, e := range ordered {
+		if e.isAlive() {
+			healthy = append(healthy, e)
+		} else {
+			dead = append(dead, e)
+		}
+	}
+	return append(healthy, dead...)
+}
+
+func (p *pool) statuses() []EndpointStatus {
+	out := make([]EndpointStatus, len(p.endpoints))
+	for i, e := range p.endpoints {
+		out[i] = e.status()
+	}
+	return out
+}
+
+func (p *pool) healthcheckLoop() {
+	defer close(p.doneCh)
+	ticker := time.NewTicker(p.healthcheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.checkAll()
+		}
+	}
+}
+
+func (p *pool) checkAll() {
+	for _, e := range p.endpoints {
+		ctx, cancel := context.WithTimeout(context.Background(), p.healthcheckTimeout)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+"/health", nil)
+		if err != nil {
+			cancel()
+			e.setAlive(false, err)
+			continue
+		}
+		resp, err := p.httpClient.Do(req)
+		cancel()
+		if err != nil {
+			e.setAlive(false, err)
+			continue
+		}
+		resp.Body.Close()
+		e.setAlive(resp.StatusCode < 500, nil)
+	}
+}
+
+func (p *pool) close() {
+	if !p.healthcheckEnabled {
+		return
+	}
+	close(p.stopCh)
+	<-p.doneCh
+}
+
+// isRetryableStatus reports whether a 5xx response should cause the
+// request to be retried against the next endpoint in the pool. Other 4xx
+// responses are assumed to be the caller's fault and are not retried
+// across endpoints, except the statuses RetryPolicy lists as retryable
+// (429 by default), which callers check separately.
+func isRetryableStatus(status int) bool {
+	return status >= 500
+}
@@ -0,0 +1,86 @@
+package agentkernel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFailoverSkipsDeadEndpoint(t *testing.T) {
+	var badHits, goodHits int
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		badHits++
+		jsonError(w, 503, "unavailable")
+	}))
+	defer bad.Close()
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		goodHits++
+		jsonOK(w, "ok")
+	}))
+	defer good.Close()
+
+	client := New(&Options{BaseURLs: []string{bad.URL, good.URL}})
+	defer client.Close()
+
+	result, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected ok, got %s", result)
+	}
+	if badHits != 1 {
+		t.Fatalf("expected the dead endpoint to be tried once, got %d", badHits)
+	}
+	if goodHits != 1 {
+		t.Fatalf("expected the healthy endpoint to be tried once, got %d", goodHits)
+	}
+}
+
+func TestFailoverDoesNotRetryOn4xx(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		jsonError(w, 400, "bad request")
+	}))
+	defer srv.Close()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("should not have retried a 4xx against the second endpoint")
+	}))
+	defer other.Close()
+
+	client := New(&Options{BaseURLs: []string{srv.URL, other.URL}})
+	defer client.Close()
+
+	_, err := client.Health(context.Background())
+	if !IsValidationError(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected 1 hit, got %d", hits)
+	}
+}
+
+func TestEndpointsReportsStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonOK(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURLs: []string{srv.URL}})
+	defer client.Close()
+
+	statuses := client.Endpoints()
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(statuses))
+	}
+	if statuses[0].BaseURL != srv.URL {
+		t.Fatalf("expected %s, got %s", srv.URL, statuses[0].BaseURL)
+	}
+	if !statuses[0].Alive {
+		t.Fatal("expected endpoint to start alive")
+	}
+}
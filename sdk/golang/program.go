@@ -0,0 +1,274 @@
+package agentkernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProgramBuildTimeout = 30 * time.Second
+	defaultProgramRunTimeout   = 10 * time.Second
+)
+
+// programRecipe describes how to build, vet, test, and run a program in
+// one of RunProgram's built-in languages, given the sorted list of file
+// paths from ProgramRequest.Files.
+type programRecipe struct {
+	// buildCmd is nil for languages with no separate build step (the
+	// run command does the compiling itself).
+	buildCmd func(files []string) []string
+	vetCmd   func(files []string) []string
+	testCmd  func(files []string) []string
+	runCmd   func(files []string) []string
+}
+
+var programRecipes = map[string]programRecipe{
+	"go": {
+		buildCmd: func(files []string) []string {
+			return append([]string{"go", "build", "-o", "/tmp/agentkernel-program"}, files...)
+		},
+		vetCmd: func(files []string) []string {
+			return append([]string{"go", "vet"}, files...)
+		},
+		testCmd: func(files []string) []string {
+			return append([]string{"go", "test"}, files...)
+		},
+		runCmd: func(files []string) []string {
+			return []string{"/tmp/agentkernel-program"}
+		},
+	},
+	"python": {
+		vetCmd: func(files []string) []string {
+			return append([]string{"python3", "-m", "py_compile"}, files...)
+		},
+		testCmd: func(files []string) []string {
+			return []string{"python3", "-m", "unittest", "discover"}
+		},
+		runCmd: func(files []string) []string {
+			return []string{"python3", entrypoint(files, "main.py", "app.py")}
+		},
+	},
+	"node": {
+		vetCmd: func(files []string) []string {
+			return append([]string{"node", "--check"}, files...)
+		},
+		testCmd: func(files []string) []string {
+			return []string{"node", "--test"}
+		},
+		runCmd: func(files []string) []string {
+			return []string{"node", entrypoint(files, "index.js", "main.js")}
+		},
+	},
+}
+
+// programRecipeFor resolves a ProgramRequest.Image to a built-in recipe by
+// matching the substring before its first ':' (so "python:3.12" and
+// "python" both select the python recipe).
+func programRecipeFor(image string) (programRecipe, bool) {
+	lang, _, _ := strings.Cut(image, ":")
+	recipe, ok := programRecipes[lang]
+	return recipe, ok
+}
+
+// entrypoint picks the first of candidates present in files, falling back
+// to the first file in sorted order if none match.
+func entrypoint(files []string, candidates ...string) string {
+	for _, c := range candidates {
+		for _, f := range files {
+			if f == c {
+				return f
+			}
+		}
+	}
+	return files[0]
+}
+
+// RunProgram builds and runs a small multi-file program inside a fresh
+// sandbox, Go-playground style: it creates the sandbox, writes
+// req.Files, runs the language's vet/build/test steps, and cleans the
+// sandbox up before returning — regardless of whether the program itself
+// succeeded.
+//
+// A build, vet, or test step that exceeds req.BuildTimeout returns
+// *BuildTimeoutError; the run step exceeding req.RunTimeout returns
+// *RunTimeoutError. A BuildTimeoutError from the vet or build step comes
+// before the program itself runs; with AsTest, one from the test step
+// comes after test code has started running.
+func (c *Client) RunProgram(ctx context.Context, req ProgramRequest) (*ProgramResponse, error) {
+	recipe, ok := programRecipeFor(req.Image)
+	if !ok {
+		return nil, fmt.Errorf("agentkernel: no built-in program recipe for image %q", req.Image)
+	}
+	if len(req.Files) == 0 {
+		return nil, fmt.Errorf("agentkernel: RunProgram requires at least one file")
+	}
+
+	buildTimeout := req.BuildTimeout
+	if buildTimeout <= 0 {
+		buildTimeout = defaultProgramBuildTimeout
+	}
+	runTimeout := req.RunTimeout
+	if runTimeout <= 0 {
+		runTimeout = defaultProgramRunTimeout
+	}
+
+	files := make([]string, 0, len(req.Files))
+	for path := range req.Files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	result := &ProgramResponse{}
+	sandboxName := fmt.Sprintf("agentkernel-program-%x", rand.Int63())
+
+	err := c.WithSandbox(ctx, sandboxName, &CreateSandboxOptions{Image: req.Image}, func(session *SandboxSession) error {
+		for _, path := range files {
+			if err := session.WriteFile(ctx, path, req.Files[path], ""); err != nil {
+				return fmt.Errorf("agentkernel: write %s: %w", path, err)
+			}
+		}
+
+		if req.WithVet && recipe.vetCmd != nil {
+			out, err := runStepCombined(ctx, session, buildTimeout, recipe.vetCmd(files))
+			result.VetOutput = out
+			result.Events = append(result.Events, parseDiagnostics(out)...)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return &BuildTimeoutError{Output: out}
+				}
+				return err
+			}
+		}
+
+		if req.AsTest {
+			out, err := runStepCombined(ctx, session, buildTimeout, recipe.testCmd(files))
+			result.Stdout = out
+			result.Events = append(result.Events, parseDiagnostics(out)...)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return &BuildTimeoutError{Output: out}
+				}
+				return err
+			}
+			return nil
+		}
+
+		if recipe.buildCmd != nil {
+			out, err := runStepCombined(ctx, session, buildTimeout, recipe.buildCmd(files))
+			result.BuildOutput = out
+			result.Events = append(result.Events, parseDiagnostics(out)...)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return &BuildTimeoutError{Output: out}
+				}
+				return err
+			}
+		}
+
+		stdout, stderr, exitCode, err := runInteractiveCaptured(ctx, c, session.name, recipe.runCmd(files), runTimeout, req.Stdin)
+		result.Stdout, result.Stderr, result.ExitCode = stdout, stderr, exitCode
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return &RunTimeoutError{Output: stdout + stderr}
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runStepCombined runs command with a bounded timeout and returns its
+// combined output, for build/vet/test steps that don't need split
+// stdout/stderr or stdin.
+func runStepCombined(ctx context.Context, session *SandboxSession, timeout time.Duration, command []string) (string, error) {
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := session.Run(stepCtx, command)
+	if err != nil {
+		if stepCtx.Err() == context.DeadlineExceeded {
+			return "", stepCtx.Err()
+		}
+		return "", err
+	}
+	return out.Output, nil
+}
+
+// runInteractiveCaptured runs command over ExecInteractive so the program
+// can receive stdin and report separate stdout/stderr, then drains its
+// output until the session ends or timeout elapses.
+func runInteractiveCaptured(ctx context.Context, client *Client, name string, command []string, timeout time.Duration, stdin string) (stdout, stderr string, exitCode int, err error) {
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	session, err := client.ExecInteractive(stepCtx, name, command, nil)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if stdin != "" {
+		if _, werr := session.Stdin.Write([]byte(stdin)); werr != nil {
+			session.Close()
+			return "", "", 0, werr
+		}
+	}
+	if cerr := session.Close(); cerr != nil {
+		return "", "", 0, cerr
+	}
+
+	var outBuf, errBuf strings.Builder
+loop:
+	for {
+		select {
+		case ev, ok := <-session.Stdout:
+			if !ok {
+				break loop
+			}
+			switch ev.Type {
+			case "stdout":
+				if content, ok := ev.Data["content"].(string); ok {
+					outBuf.WriteString(content)
+				}
+			case "stderr":
+				if content, ok := ev.Data["content"].(string); ok {
+					errBuf.WriteString(content)
+				}
+			case "exit":
+				if code, ok := ev.Data["exit_code"].(float64); ok {
+					exitCode = int(code)
+				}
+			}
+		case <-stepCtx.Done():
+			return outBuf.String(), errBuf.String(), 0, stepCtx.Err()
+		}
+	}
+	return outBuf.String(), errBuf.String(), exitCode, nil
+}
+
+// diagnosticPattern matches the "file:line:col: message" format shared by
+// the Go compiler/vet and, closely enough to be useful, Node's --check.
+var diagnosticPattern = regexp.MustCompile(`(?m)^([^\s:][^:]*):(\d+):(\d+):\s*(.+)$`)
+
+// parseDiagnostics extracts per-file compiler/linter findings from build
+// or vet output. Output that doesn't match the recognized format (as with
+// Python tracebacks) simply yields no diagnostics — Events is best-effort,
+// not a substitute for BuildOutput/VetOutput.
+func parseDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, m := range diagnosticPattern.FindAllStringSubmatch(output, -1) {
+		line, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		diags = append(diags, Diagnostic{File: m[1], Line: line, Col: col, Message: m[4]})
+	}
+	return diags
+}
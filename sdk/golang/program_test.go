@@ -0,0 +1,121 @@
+package agentkernel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunProgramGoHappyPath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			jsonOK(w, map[string]string{"name": "tmp", "status": "running", "backend": "docker"})
+		case r.Method == http.MethodDelete:
+			jsonOK(w, "removed")
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/files/"):
+			jsonOK(w, "ok")
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			jsonOK(w, map[string]string{"output": ""})
+		case strings.HasSuffix(r.URL.Path, "/exec/stream"):
+			if err := http.NewResponseController(w).EnableFullDuplex(); err != nil {
+				t.Fatal(err)
+			}
+			flusher := w.(http.Flusher)
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				line := scanner.Text()
+				if strings.Contains(line, `"type":"start"`) {
+					w.Write([]byte(`{"type":"stdout","content":"hello\n"}` + "\n"))
+					flusher.Flush()
+				}
+				if strings.Contains(line, `"type":"close"`) {
+					w.Write([]byte(`{"type":"exit","exit_code":0}` + "\n"))
+					flusher.Flush()
+					return
+				}
+			}
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+	defer client.Close()
+
+	resp, err := client.RunProgram(context.Background(), ProgramRequest{
+		Files: map[string]string{"main.go": "package main\nfunc main() {}\n"},
+		Image: "go:1.21",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stdout != "hello\n" {
+		t.Fatalf("expected stdout %q, got %q", "hello\n", resp.Stdout)
+	}
+	if resp.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", resp.ExitCode)
+	}
+}
+
+func TestRunProgramUnknownImage(t *testing.T) {
+	client := New(&Options{BaseURL: "http://unused"})
+	defer client.Close()
+
+	_, err := client.RunProgram(context.Background(), ProgramRequest{
+		Files: map[string]string{"main.rb": "puts 1"},
+		Image: "ruby:3.2",
+	})
+	if err == nil {
+		t.Fatal("expected error for unrecognized image")
+	}
+}
+
+func TestRunProgramBuildTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			jsonOK(w, map[string]string{"name": "tmp", "status": "running", "backend": "docker"})
+		case r.Method == http.MethodDelete:
+			jsonOK(w, "removed")
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/files/"):
+			jsonOK(w, "ok")
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			time.Sleep(50 * time.Millisecond)
+			jsonOK(w, map[string]string{"output": "still building"})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+	defer client.Close()
+
+	_, err := client.RunProgram(context.Background(), ProgramRequest{
+		Files:        map[string]string{"main.go": "package main\nfunc main() {}\n"},
+		Image:        "go:1.21",
+		BuildTimeout: 5 * time.Millisecond,
+	})
+	var timeoutErr *BuildTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *BuildTimeoutError, got %v (%T)", err, err)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	out := "./main.go:3:2: undefined: foo\nsome unrelated line\n"
+	diags := parseDiagnostics(out)
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	if diags[0].File != "./main.go" || diags[0].Line != 3 || diags[0].Col != 2 {
+		t.Fatalf("unexpected diagnostic: %+v", diags[0])
+	}
+}
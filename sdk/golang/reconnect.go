@@ -0,0 +1,196 @@
+package agentkernel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// StreamReconnectPolicy configures automatic reconnection for RunStream and
+// SandboxSession.ExecStream when the connection drops mid-stream, before a
+// terminal "exit"/"done" event arrives. This is the same problem
+// Kubernetes' watch and etcd's v2 client solve for long-lived HTTP streams
+// that outlive a proxy's idle timeout: reconnect with the last event ID
+// seen, and let the server resume from there.
+type StreamReconnectPolicy struct {
+	// Enabled turns on reconnection. Default: false — a dropped stream
+	// just ends: the channel closes after the last event received, with no
+	// "error" RawStreamEvent for the drop.
+	Enabled bool
+
+	// MaxAttempts caps how many times a dropped stream is redialed. 0
+	// means unlimited while Enabled is true.
+	MaxAttempts int
+
+	// Backoff is the delay before the first reconnect attempt. Later
+	// attempts back off exponentially, doubling each time up to MaxBackoff.
+	Backoff time.Duration
+
+	// MaxBackoff caps the computed delay before jitter is applied. Default: 30s.
+	MaxBackoff time.Duration
+}
+
+// backoffFor computes the delay before reconnect attempt n (1-indexed), as
+// min(MaxBackoff, Backoff * 2^(n-1)) plus uniform jitter in [0, backoff/2),
+// the same scheme RetryPolicy.backoffFor uses for unary retries.
+func (p *StreamReconnectPolicy) backoffFor(n int) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	backoff := p.Backoff << (n - 1)
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(0)
+	if half := int64(backoff) / 2; half > 0 {
+		jitter = time.Duration(rand.Int63n(half))
+	}
+	return backoff + jitter
+}
+
+// streamDialer opens one attempt at a streaming connection. If
+// lastEventID is non-empty it's sent as Last-Event-ID, so a server that
+// honors it can resume the stream rather than starting over.
+type streamDialer func(ctx context.Context, lastEventID string) (*http.Response, error)
+
+// dialSSE performs one pool-failover-and-retry attempt at a POST streaming
+// request, the same way Client.requestAttempt does for unary calls, and
+// sets Last-Event-ID when lastEventID is non-empty.
+func (c *Client) dialSSE(ctx context.Context, path string, jsonBody []byte, lastEventID string) (*http.Response, error) {
+	maxRetries := c.retryPolicy.maxRetries()
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		retryAfterHint := time.Duration(0)
+		for _, ep := range c.pool.candidates() {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+				ep.baseURL+path, bytes.NewReader(jsonBody))
+			if err != nil {
+				return nil, err
+			}
+			c.applyHeaders(req)
+			req.Header.Set("Accept", "text/event-stream")
+			if lastEventID != "" {
+				req.Header.Set("Last-Event-ID", lastEventID)
+			}
+
+			resp, err := c.httpClient.Do(req)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if resp.StatusCode >= 400 {
+				apiErr := c.handleErrorResponse(resp)
+				resp.Body.Close()
+				if isRetryableStatus(resp.StatusCode) || c.retryPolicy.isRetryableStatusCode(resp.StatusCode) {
+					lastErr = apiErr
+					if d, ok := retryAfter(resp); ok {
+						retryAfterHint = d
+					}
+					continue
+				}
+				return nil, apiErr
+			}
+			return resp, nil
+		}
+		if attempt >= maxRetries {
+			return nil, lastErr
+		}
+
+		delay := retryAfterHint
+		if delay <= 0 {
+			delay = c.retryPolicy.backoffFor(attempt + 1)
+		}
+		c.retryPolicy.onRetry(attempt+1, lastErr, delay)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isTerminalEventType reports whether typ marks the end of a run or exec:
+// "exit" is what the typed hierarchy (see ExitEvent) calls it; "done" is
+// accepted too since that's what the untyped wire format has historically
+// used (see TestStreamParsing).
+func isTerminalEventType(typ string) bool {
+	return typ == "exit" || typ == "done"
+}
+
+// streamWithReconnect forwards every RawStreamEvent read from resp to the
+// returned channel and — if c.streamReconnect is enabled — transparently
+// redials with dial, passing the Last-Event-ID it last saw, whenever the
+// stream drops before a terminal event. To the caller this looks like one
+// uninterrupted stream. resp is the already-established first connection,
+// so the caller can report a dial failure for that first attempt
+// synchronously instead of only as an "error" RawStreamEvent.
+//
+// After a reconnect, if the first event the resumed stream delivers
+// carries the same ID the previous attempt last saw, it's dropped: servers
+// that honor Last-Event-ID commonly redeliver that one event so the client
+// can confirm where the server picked back up.
+func (c *Client) streamWithReconnect(ctx context.Context, resp *http.Response, dial streamDialer) <-chan RawStreamEvent {
+	out := make(chan RawStreamEvent)
+
+	go func() {
+		defer close(out)
+
+		var lastEventID string
+		attempts := 0
+		var err error
+
+		for {
+			if err != nil {
+				out <- RawStreamEvent{Type: "error", Data: map[string]interface{}{"message": err.Error()}}
+				return
+			}
+
+			resumeID := lastEventID
+			skipResumeDuplicate := attempts > 0
+			terminal := func() bool {
+				defer resp.Body.Close()
+				for ev := range ParseSSE(resp.Body) {
+					lastEventID = ev.ID
+					if skipResumeDuplicate {
+						skipResumeDuplicate = false
+						if ev.ID != "" && ev.ID == resumeID {
+							continue
+						}
+					}
+					out <- ev
+					if isTerminalEventType(ev.Type) {
+						return true
+					}
+				}
+				return false
+			}()
+			if terminal {
+				return
+			}
+			if !c.streamReconnect.Enabled {
+				return
+			}
+			if c.streamReconnect.MaxAttempts > 0 && attempts >= c.streamReconnect.MaxAttempts {
+				out <- RawStreamEvent{Type: "error", Data: map[string]interface{}{
+					"message": fmt.Sprintf("agentkernel: stream reconnect attempts exhausted after %d", attempts),
+				}}
+				return
+			}
+			attempts++
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.streamReconnect.backoffFor(attempts)):
+			}
+			resp, err = dial(ctx, lastEventID)
+		}
+	}()
+
+	return out
+}
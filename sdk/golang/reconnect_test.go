@@ -0,0 +1,112 @@
+package agentkernel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunStreamReconnectsAfterDrop(t *testing.T) {
+	var attempt int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempt, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		if n == 1 {
+			if got := r.Header.Get("Last-Event-ID"); got != "" {
+				t.Errorf("expected no Last-Event-ID on first attempt, got %q", got)
+			}
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, "id: 1\nevent: output\ndata: {\"content\":\"first\"}\n\n")
+			w.(http.Flusher).Flush()
+
+			// Simulate a dropped connection: hijack and close before the
+			// terminal event, so the client has to reconnect.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+
+		if got := r.Header.Get("Last-Event-ID"); got != "1" {
+			t.Errorf("expected Last-Event-ID 1 on reconnect, got %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+		// The server redelivers the last event it knows the client saw,
+		// which the client is expected to drop as a duplicate.
+		io.WriteString(w, "id: 1\nevent: output\ndata: {\"content\":\"first\"}\n\n")
+		io.WriteString(w, "id: 2\nevent: exit\ndata: {\"exit_code\":0}\n\n")
+		w.(http.Flusher).Flush()
+	}))
+	defer srv.Close()
+
+	client := New(&Options{
+		BaseURL:         srv.URL,
+		StreamReconnect: StreamReconnectPolicy{Enabled: true, Backoff: time.Millisecond},
+	})
+
+	ch, err := client.RunStream(context.Background(), []string{"echo", "hi"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []RawStreamEvent
+	for ev := range ch {
+		got = append(got, ev)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events (duplicate resume event dropped), got %d: %+v", len(got), got)
+	}
+	if got[0].Type != "output" || got[0].Data["content"] != "first" {
+		t.Fatalf("expected first output event, got %+v", got[0])
+	}
+	if got[1].Type != "exit" {
+		t.Fatalf("expected exit event, got %+v", got[1])
+	}
+	if n := atomic.LoadInt32(&attempt); n != 2 {
+		t.Fatalf("expected 2 connection attempts, got %d", n)
+	}
+}
+
+func TestRunStreamWithoutReconnectEndsOnDrop(t *testing.T) {
+	var attempt int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempt, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "id: 1\nevent: output\ndata: {\"content\":\"first\"}\n\n")
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	client := New(&Options{BaseURL: srv.URL})
+
+	ch, err := client.RunStream(context.Background(), []string{"echo", "hi"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []RawStreamEvent
+	for ev := range ch {
+		got = append(got, ev)
+	}
+
+	if len(got) != 1 || got[0].Type != "output" {
+		t.Fatalf("expected just the one output event before the stream ended, got %+v", got)
+	}
+	if n := atomic.LoadInt32(&attempt); n != 1 {
+		t.Fatalf("expected no reconnect attempt, got %d connection attempts", n)
+	}
+}
@@ -0,0 +1,112 @@
+package agentkernel
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var defaultRetryableStatusCodes = []int{429, 502, 503, 504}
+
+// RetryPolicy configures automatic retries for transient failures in
+// Client.request and Client.RunStream. A nil policy (the default) disables
+// retries entirely: each pool endpoint is tried exactly once per call.
+type RetryPolicy struct {
+	// MaxRetries is the number of additional attempts after the first.
+	// MaxRetries of 0 disables retrying.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry. Default: 200ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the computed delay before jitter is applied. Default: 10s.
+	MaxBackoff time.Duration
+
+	// RetryableStatusCodes lists HTTP statuses that should be retried.
+	// Defaults to 429, 502, 503, 504.
+	RetryableStatusCodes []int
+
+	// OnRetry, if set, is called before each retry sleep with the attempt
+	// number (starting at 1), the error that triggered the retry, and the
+	// delay about to be waited.
+	OnRetry func(attempt int, err error, next time.Duration)
+}
+
+func (p *RetryPolicy) maxRetries() int {
+	if p == nil {
+		return 0
+	}
+	return p.MaxRetries
+}
+
+func (p *RetryPolicy) initialBackoff() time.Duration {
+	if p == nil || p.InitialBackoff <= 0 {
+		return 200 * time.Millisecond
+	}
+	return p.InitialBackoff
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p == nil || p.MaxBackoff <= 0 {
+		return 10 * time.Second
+	}
+	return p.MaxBackoff
+}
+
+func (p *RetryPolicy) retryableStatusCodes() []int {
+	if p == nil || len(p.RetryableStatusCodes) == 0 {
+		return defaultRetryableStatusCodes
+	}
+	return p.RetryableStatusCodes
+}
+
+func (p *RetryPolicy) isRetryableStatusCode(status int) bool {
+	for _, c := range p.retryableStatusCodes() {
+		if c == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) onRetry(attempt int, err error, next time.Duration) {
+	if p != nil && p.OnRetry != nil {
+		p.OnRetry(attempt, err, next)
+	}
+}
+
+// backoffFor computes the delay before retry attempt n (1-indexed),
+// as min(MaxBackoff, InitialBackoff * 2^(n-1)) plus uniform jitter in
+// [0, backoff/2).
+func (p *RetryPolicy) backoffFor(n int) time.Duration {
+	backoff := p.initialBackoff() << (n - 1)
+	if max := p.maxBackoff(); backoff > max || backoff <= 0 {
+		backoff = max
+	}
+	jitter := time.Duration(0)
+	if half := int64(backoff) / 2; half > 0 {
+		jitter = time.Duration(rand.Int63n(half))
+	}
+	return backoff + jitter
+}
+
+// retryAfter parses a Retry-After header (seconds or HTTP-date) and returns
+// the delay it specifies, or ok=false if the header is absent or malformed.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
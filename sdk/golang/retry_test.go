@@ -0,0 +1,193 @@
+package agentkernel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetrySucceedsAfterTransientFailures(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			jsonError(w, 503, "try again")
+			return
+		}
+		jsonOK(w, "ok")
+	}))
+	defer srv.Close()
+
+	var retries []int
+	client := New(&Options{
+		BaseURL: srv.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries:     5,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+			OnRetry: func(attempt int, err error, next time.Duration) {
+				retries = append(retries, attempt)
+			},
+		},
+	})
+	defer client.Close()
+
+	result, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected ok, got %s", result)
+	}
+	if hits != 3 {
+		t.Fatalf("expected 3 attempts, got %d", hits)
+	}
+	if len(retries) != 2 {
+		t.Fatalf("expected 2 retries, got %v", retries)
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		jsonError(w, 503, "down")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{
+		BaseURL: srv.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+	})
+	defer client.Close()
+
+	_, err := client.Health(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if hits != 3 {
+		t.Fatalf("expected 3 attempts (1 + 2 retries), got %d", hits)
+	}
+}
+
+func TestRetryNeverRetries4xx(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		jsonError(w, 404, "not found")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{
+		BaseURL:     srv.URL,
+		RetryPolicy: &RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond},
+	})
+	defer client.Close()
+
+	_, err := client.Health(context.Background())
+	if !IsNotFoundError(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected 1 attempt, got %d", hits)
+	}
+}
+
+func TestRetryRetries429(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			jsonError(w, 429, "rate limited")
+			return
+		}
+		jsonOK(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{
+		BaseURL: srv.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries:     5,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		},
+	})
+	defer client.Close()
+
+	result, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected ok, got %s", result)
+	}
+	if hits != 3 {
+		t.Fatalf("expected 3 attempts, got %d", hits)
+	}
+}
+
+func TestRetryHonorsRetryAfterHeader(t *testing.T) {
+	var hits int
+	var firstHitAt, secondHitAt time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits == 1 {
+			firstHitAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			jsonError(w, 429, "rate limited")
+			return
+		}
+		secondHitAt = time.Now()
+		jsonOK(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{
+		BaseURL: srv.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries:     1,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+	})
+	defer client.Close()
+
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gap := secondHitAt.Sub(firstHitAt); gap < 900*time.Millisecond {
+		t.Fatalf("expected retry to wait for the 1s Retry-After header, only waited %s", gap)
+	}
+}
+
+func TestRetryHonorsContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonError(w, 503, "down")
+	}))
+	defer srv.Close()
+
+	client := New(&Options{
+		BaseURL: srv.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries:     100,
+			InitialBackoff: 50 * time.Millisecond,
+			MaxBackoff:     50 * time.Millisecond,
+		},
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Health(ctx)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -14,6 +14,12 @@ func (s *SandboxSession) Run(ctx context.Context, command []string) (*RunOutput,
 	return s.client.ExecInSandbox(ctx, s.name, command)
 }
 
+// ExecStream executes a command in the sandbox with SSE streaming output.
+// See Client.ExecStream.
+func (s *SandboxSession) ExecStream(ctx context.Context, command []string) (<-chan RawStreamEvent, error) {
+	return s.client.ExecStream(ctx, s.name, command)
+}
+
 // Info returns the sandbox's current info.
 func (s *SandboxSession) Info(ctx context.Context) (*SandboxInfo, error) {
 	return s.client.GetSandbox(ctx, s.name)
@@ -3,41 +3,245 @@ package agentkernel
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ParseSSE reads SSE events from r and sends them on the returned channel.
 // The channel is closed when the stream ends or an error occurs.
-// Errors are returned as StreamEvent with Type "error".
-func ParseSSE(r io.Reader) <-chan StreamEvent {
-	ch := make(chan StreamEvent)
+// A read error ends the stream without an event of its own; a data:
+// payload that isn't valid JSON is delivered under its event's type with
+// Data {"raw": payload}.
+//
+// Like ParseTypedSSE, this reads with a bufio.Reader line loop rather than
+// bufio.Scanner, so a single data: line isn't bounded by Scanner's 64KB
+// token limit — a real risk here, since tool output routinely exceeds
+// that in one chunk. Multi-line data: fields are folded together with
+// "\n", per the SSE spec, before being parsed as JSON.
+func ParseSSE(r io.Reader) <-chan RawStreamEvent {
+	ch := make(chan RawStreamEvent)
 	go func() {
 		defer close(ch)
-		scanner := bufio.NewScanner(r)
-		var eventType string
-		for scanner.Scan() {
-			line := scanner.Text()
+		reader := bufio.NewReader(r)
+		var eventType, lastEventID string
+		var dataLines []string
+
+		dispatch := func() {
+			if eventType == "" && len(dataLines) == 0 {
+				return
+			}
+			dataStr := strings.Join(dataLines, "\n")
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(dataStr), &data); err != nil {
+				data = map[string]interface{}{"raw": dataStr}
+			}
+			typ := eventType
+			if typ == "" {
+				typ = "message"
+			}
+			ch <- RawStreamEvent{Type: typ, Data: data, ID: lastEventID}
+			eventType, dataLines = "", nil
+		}
+
+		for {
+			line, readErr := reader.ReadString('\n')
+			line = strings.TrimRight(line, "\r\n")
+
 			switch {
 			case strings.HasPrefix(line, "event: "):
 				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "id: "):
+				lastEventID = strings.TrimPrefix(line, "id: ")
 			case strings.HasPrefix(line, "data: "):
-				dataStr := strings.TrimPrefix(line, "data: ")
-				var data map[string]interface{}
-				if err := json.Unmarshal([]byte(dataStr), &data); err != nil {
-					data = map[string]interface{}{"raw": dataStr}
-				}
-				typ := eventType
-				if typ == "" {
-					typ = "message"
-				}
-				ch <- StreamEvent{Type: typ, Data: data}
-				eventType = ""
+				dataLines = append(dataLines, strings.TrimPrefix(line, "data: "))
 			case line == "":
-				// Empty line separates events, reset
-				eventType = ""
+				// Empty line separates events. Per the SSE spec, id: is
+				// not reset here — it persists until the next id: line.
+				dispatch()
+			}
+
+			if readErr != nil {
+				dispatch()
+				return
 			}
 		}
 	}()
 	return ch
 }
+
+// StreamEvent is implemented by every typed event ParseTypedSSE can decode:
+// StartedEvent, OutputEvent, ExitEvent, ErrorEvent, and StageEvent.
+type StreamEvent interface {
+	isStreamEvent()
+}
+
+// StartedEvent reports that a streaming run or exec began.
+type StartedEvent struct {
+	Sandbox string `json:"sandbox"`
+}
+
+func (StartedEvent) isStreamEvent() {}
+
+// OutputEvent carries a chunk of output from a running process's stdout or
+// stderr.
+type OutputEvent struct {
+	Stream  string `json:"stream"`
+	Content string `json:"content"`
+}
+
+func (OutputEvent) isStreamEvent() {}
+
+// ExitEvent reports the exit code and wall-clock duration of a finished run.
+type ExitEvent struct {
+	ExitCode   int   `json:"exit_code"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+func (ExitEvent) isStreamEvent() {}
+
+// ErrorEvent reports a server-side error that ended the stream.
+type ErrorEvent struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+func (ErrorEvent) isStreamEvent() {}
+
+// StageEvent reports that a streaming run advanced to a new named stage
+// (e.g. "build", "test"), for server-side tasks broken into phases.
+type StageEvent struct {
+	Stage string `json:"stage"`
+}
+
+func (StageEvent) isStreamEvent() {}
+
+// ParseTypedSSEOptions configures ParseTypedSSE.
+type ParseTypedSSEOptions struct {
+	// OnRetry, if set, is called whenever the stream sends a retry: field,
+	// with the server-suggested reconnection delay.
+	OnRetry func(delay time.Duration)
+
+	// OnEventID, if set, is called whenever the stream sends an id: field.
+	// Callers that resume a dropped stream with a Last-Event-ID header can
+	// use this to track the most recent ID seen.
+	OnEventID func(id string)
+}
+
+// ParseTypedSSE reads a stream of typed SSE events from r, dispatching each
+// event once its blank-line terminator arrives. Multi-line data: fields are
+// folded together with "\n", per the SSE spec, before being decoded into
+// the concrete type named by the preceding event: field. A data: payload
+// that doesn't decode into the schema its event: name implies, or an
+// event: name this package doesn't know, is sent on the returned error
+// channel rather than embedded in a synthetic event. Both channels are
+// closed when the stream ends.
+//
+// Like ParseSSE, this reads with a bufio.Reader line loop instead of
+// bufio.Scanner, so a single event isn't bounded by Scanner's 64KB token
+// limit.
+func ParseTypedSSE(r io.Reader, opts *ParseTypedSSEOptions) (<-chan StreamEvent, <-chan error) {
+	events := make(chan StreamEvent)
+	errs := make(chan error)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		reader := bufio.NewReader(r)
+		var eventType string
+		var dataLines []string
+
+		dispatch := func() {
+			if eventType == "" && len(dataLines) == 0 {
+				return
+			}
+			typ, data := eventType, []byte(strings.Join(dataLines, "\n"))
+			eventType, dataLines = "", nil
+
+			event, err := decodeTypedEvent(typ, data)
+			if err != nil {
+				errs <- err
+				return
+			}
+			events <- event
+		}
+
+		for {
+			line, readErr := reader.ReadString('\n')
+			line = strings.TrimRight(line, "\r\n")
+
+			switch {
+			case strings.HasPrefix(line, ":"):
+				// comment line, ignore
+			case line == "":
+				dispatch()
+			default:
+				field, value, _ := strings.Cut(line, ":")
+				value = strings.TrimPrefix(value, " ")
+				switch field {
+				case "event":
+					eventType = value
+				case "data":
+					dataLines = append(dataLines, value)
+				case "id":
+					if opts != nil && opts.OnEventID != nil {
+						opts.OnEventID(value)
+					}
+				case "retry":
+					if ms, convErr := strconv.Atoi(value); convErr == nil && opts != nil && opts.OnRetry != nil {
+						opts.OnRetry(time.Duration(ms) * time.Millisecond)
+					}
+				}
+			}
+
+			if readErr != nil {
+				dispatch()
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+func decodeTypedEvent(eventType string, data []byte) (StreamEvent, error) {
+	switch eventType {
+	case "started":
+		var e StartedEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("agentkernel: decode started event: %w", err)
+		}
+		return e, nil
+	case "output":
+		var e OutputEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("agentkernel: decode output event: %w", err)
+		}
+		return e, nil
+	case "exit", "done":
+		// "done" is the untyped wire format's name for the same event;
+		// see isTerminalEventType.
+		var e ExitEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("agentkernel: decode exit event: %w", err)
+		}
+		return e, nil
+	case "error":
+		var e ErrorEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("agentkernel: decode error event: %w", err)
+		}
+		return e, nil
+	case "stage":
+		var e StageEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("agentkernel: decode stage event: %w", err)
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("agentkernel: unknown SSE event type %q", eventType)
+	}
+}
@@ -0,0 +1,124 @@
+package agentkernel
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTypedSSEDecodesKnownEvents(t *testing.T) {
+	sse := "" +
+		"event: started\ndata: {\"sandbox\":\"test\"}\n\n" +
+		"event: output\ndata: {\"stream\":\"stdout\",\"content\":\"hello\"}\n\n" +
+		"event: exit\ndata: {\"exit_code\":0,\"duration_ms\":42}\n\n"
+
+	events, errs := ParseTypedSSE(strings.NewReader(sse), nil)
+
+	var got []StreamEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range errs {
+			t.Errorf("unexpected parse error: %v", err)
+		}
+	}()
+	for ev := range events {
+		got = append(got, ev)
+	}
+	<-done
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(got), got)
+	}
+	started, ok := got[0].(StartedEvent)
+	if !ok || started.Sandbox != "test" {
+		t.Fatalf("expected StartedEvent{Sandbox: test}, got %+v", got[0])
+	}
+	out, ok := got[1].(OutputEvent)
+	if !ok || out.Stream != "stdout" || out.Content != "hello" {
+		t.Fatalf("expected OutputEvent{stdout, hello}, got %+v", got[1])
+	}
+	exit, ok := got[2].(ExitEvent)
+	if !ok || exit.ExitCode != 0 || exit.DurationMs != 42 {
+		t.Fatalf("expected ExitEvent{0, 42}, got %+v", got[2])
+	}
+}
+
+func TestParseTypedSSEFoldsMultilineData(t *testing.T) {
+	sse := "event: output\ndata: {\"stream\":\"stdout\",\n" +
+		"data: \"content\":\"hi\"}\n\n"
+
+	events, errs := ParseTypedSSE(strings.NewReader(sse), nil)
+	go func() {
+		for range errs {
+		}
+	}()
+
+	ev, ok := <-events
+	if !ok {
+		t.Fatal("expected one event")
+	}
+	out, ok := ev.(OutputEvent)
+	if !ok || out.Content != "hi" {
+		t.Fatalf("expected folded OutputEvent with content hi, got %+v", ev)
+	}
+}
+
+func TestParseTypedSSEReportsUnknownEventOnErrorChannel(t *testing.T) {
+	sse := "event: unknown\ndata: {}\n\n"
+	events, errs := ParseTypedSSE(strings.NewReader(sse), nil)
+
+	go func() {
+		for range events {
+			t.Error("expected no events")
+		}
+	}()
+
+	err, ok := <-errs
+	if !ok || err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestParseTypedSSESurfacesRetryAndEventID(t *testing.T) {
+	sse := "id: abc-1\nretry: 250\nevent: started\ndata: {\"sandbox\":\"test\"}\n\n"
+
+	var gotRetry time.Duration
+	var gotID string
+	events, errs := ParseTypedSSE(strings.NewReader(sse), &ParseTypedSSEOptions{
+		OnRetry:   func(d time.Duration) { gotRetry = d },
+		OnEventID: func(id string) { gotID = id },
+	})
+	go func() {
+		for range errs {
+		}
+	}()
+	<-events
+
+	if gotRetry != 250*time.Millisecond {
+		t.Fatalf("expected 250ms retry hint, got %s", gotRetry)
+	}
+	if gotID != "abc-1" {
+		t.Fatalf("expected event id abc-1, got %q", gotID)
+	}
+}
+
+func TestParseTypedSSEHandlesLinesOverScannerLimit(t *testing.T) {
+	big := strings.Repeat("x", 100*1024) // bigger than bufio.Scanner's 64KB default
+	sse := "event: output\ndata: {\"stream\":\"stdout\",\"content\":\"" + big + "\"}\n\n"
+
+	events, errs := ParseTypedSSE(strings.NewReader(sse), nil)
+	go func() {
+		for range errs {
+		}
+	}()
+
+	ev, ok := <-events
+	if !ok {
+		t.Fatal("expected one event")
+	}
+	out, ok := ev.(OutputEvent)
+	if !ok || len(out.Content) != len(big) {
+		t.Fatalf("expected output content of length %d, got %d", len(big), len(out.Content))
+	}
+}
@@ -0,0 +1,128 @@
+package agentkernel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamHandler receives typed events from Client.RunStreamWithHandler and
+// SandboxSession.RunStream, one method per event StreamEvent can carry
+// except StartedEvent, which has no method and is not delivered.
+// Implementations that don't care about a particular event can leave the
+// corresponding method empty — see StreamToWriters for a minimal example.
+type StreamHandler interface {
+	// OnStdout is called for each OutputEvent on the "stdout" stream.
+	OnStdout(data []byte)
+	// OnStderr is called for each OutputEvent on the "stderr" stream.
+	OnStderr(data []byte)
+	// OnExit is called once, with the process's exit code, when an
+	// ExitEvent ends the stream.
+	OnExit(code int)
+	// OnStageChange is called for each StageEvent a multi-phase task
+	// reports as it progresses.
+	OnStageChange(stage string)
+	// OnError is called for each ErrorEvent, including those the client
+	// synthesizes when a reconnect dial fails or reconnect attempts run
+	// out, and for each event that fails to decode. A stream that drops
+	// with reconnection disabled just ends without an OnError call.
+	OnError(err error)
+}
+
+// RunStreamWithHandler runs command with SSE streaming output like
+// RunStream, but decodes each event into its typed form (see StreamEvent)
+// and dispatches it to handler's typed callbacks instead of making the
+// caller type-switch on a channel. It honors Options.StreamReconnect the
+// same way RunStream does, and returns once the stream ends.
+func (c *Client) RunStreamWithHandler(ctx context.Context, command []string, opts *RunOptions, handler StreamHandler) error {
+	ch, err := c.RunStream(ctx, command, opts)
+	if err != nil {
+		return err
+	}
+	for ev := range ch {
+		dispatchRawEvent(handler, ev)
+	}
+	return nil
+}
+
+// RunStream executes command in the sandbox with SSE streaming output,
+// decoded into typed events and dispatched to handler. It's the
+// existing-sandbox counterpart to Client.RunStreamWithHandler, and streams
+// over the same endpoint as ExecStream.
+func (s *SandboxSession) RunStream(ctx context.Context, command []string, handler StreamHandler) error {
+	ch, err := s.client.ExecStream(ctx, s.name, command)
+	if err != nil {
+		return err
+	}
+	for ev := range ch {
+		dispatchRawEvent(handler, ev)
+	}
+	return nil
+}
+
+// dispatchRawEvent decodes a RawStreamEvent — the form RunStream and
+// ExecStream's channels carry — into its typed StreamEvent and dispatches
+// it to handler. Decode failures are reported through OnError rather than
+// stopping the dispatch loop, as are "error" events, including those
+// streamWithReconnect synthesizes for a transport failure.
+func dispatchRawEvent(handler StreamHandler, ev RawStreamEvent) {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		handler.OnError(fmt.Errorf("agentkernel: re-encode stream event: %w", err))
+		return
+	}
+	typed, err := decodeTypedEvent(ev.Type, data)
+	if err != nil {
+		handler.OnError(err)
+		return
+	}
+	dispatchStreamEvent(handler, typed)
+}
+
+func dispatchStreamEvent(handler StreamHandler, ev StreamEvent) {
+	switch e := ev.(type) {
+	case OutputEvent:
+		if e.Stream == "stderr" {
+			handler.OnStderr([]byte(e.Content))
+		} else {
+			handler.OnStdout([]byte(e.Content))
+		}
+	case ExitEvent:
+		handler.OnExit(e.ExitCode)
+	case StageEvent:
+		handler.OnStageChange(e.Stage)
+	case ErrorEvent:
+		handler.OnError(fmt.Errorf("agentkernel: %s", e.Message))
+	case StartedEvent:
+		// No StreamHandler method corresponds to this one.
+	}
+}
+
+// StreamToWriters returns a StreamHandler that copies stdout and stderr
+// content to the given writers and ignores everything else — the simplest
+// way to pipe a streaming Run or exec straight through, e.g. to os.Stdout
+// and os.Stderr.
+func StreamToWriters(stdout, stderr io.Writer) StreamHandler {
+	return &writerStreamHandler{stdout: stdout, stderr: stderr}
+}
+
+type writerStreamHandler struct {
+	stdout, stderr io.Writer
+}
+
+func (h *writerStreamHandler) OnStdout(data []byte) {
+	if h.stdout != nil {
+		h.stdout.Write(data) //nolint:errcheck
+	}
+}
+
+func (h *writerStreamHandler) OnStderr(data []byte) {
+	if h.stderr != nil {
+		h.stderr.Write(data) //nolint:errcheck
+	}
+}
+
+func (h *writerStreamHandler) OnExit(int)           {}
+func (h *writerStreamHandler) OnStageChange(string) {}
+func (h *writerStreamHandler) OnError(error)        {}
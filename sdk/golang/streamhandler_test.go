@@ -0,0 +1,106 @@
+package agentkernel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type recordingHandler struct {
+	stdout, stderr []string
+	stages         []string
+	errs           []string
+	exitCode       int
+	exited         bool
+}
+
+func (h *recordingHandler) OnStdout(data []byte)   { h.stdout = append(h.stdout, string(data)) }
+func (h *recordingHandler) OnStderr(data []byte)   { h.stderr = append(h.stderr, string(data)) }
+func (h *recordingHandler) OnStageChange(s string) { h.stages = append(h.stages, s) }
+func (h *recordingHandler) OnError(err error)      { h.errs = append(h.errs, err.Error()) }
+func (h *recordingHandler) OnExit(code int) {
+	h.exited = true
+	h.exitCode = code
+}
+
+func TestRunStreamWithHandlerDispatchesTypedEvents(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w,
+			"event: stage\ndata: {\"stage\":\"build\"}\n\n"+
+				"event: output\ndata: {\"stream\":\"stdout\",\"content\":\"hello\"}\n\n"+
+				"event: output\ndata: {\"stream\":\"stderr\",\"content\":\"uh oh\"}\n\n"+
+				"event: exit\ndata: {\"exit_code\":3}\n\n")
+		w.(http.Flusher).Flush()
+	})
+	defer srv.Close()
+
+	handler := &recordingHandler{}
+	err := client.RunStreamWithHandler(context.Background(), []string{"echo", "hi"}, nil, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.stages) != 1 || handler.stages[0] != "build" {
+		t.Fatalf("expected stage build, got %v", handler.stages)
+	}
+	if len(handler.stdout) != 1 || handler.stdout[0] != "hello" {
+		t.Fatalf("expected stdout hello, got %v", handler.stdout)
+	}
+	if len(handler.stderr) != 1 || handler.stderr[0] != "uh oh" {
+		t.Fatalf("expected stderr uh oh, got %v", handler.stderr)
+	}
+	if !handler.exited || handler.exitCode != 3 {
+		t.Fatalf("expected exit code 3, got exited=%v code=%d", handler.exited, handler.exitCode)
+	}
+}
+
+func TestSandboxSessionRunStreamDispatchesTypedEvents(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/my-sb/exec/events" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "event: done\ndata: {\"exit_code\":0}\n\n")
+		w.(http.Flusher).Flush()
+	})
+	defer srv.Close()
+
+	session := &SandboxSession{name: "my-sb", client: client}
+	handler := &recordingHandler{}
+	if err := session.RunStream(context.Background(), []string{"make"}, handler); err != nil {
+		t.Fatal(err)
+	}
+	if !handler.exited || handler.exitCode != 0 {
+		t.Fatalf("expected exit code 0, got exited=%v code=%d", handler.exited, handler.exitCode)
+	}
+}
+
+func TestStreamToWritersCopiesOutput(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w,
+			"event: output\ndata: {\"stream\":\"stdout\",\"content\":\"out\"}\n\n"+
+				"event: output\ndata: {\"stream\":\"stderr\",\"content\":\"err\"}\n\n"+
+				"event: exit\ndata: {\"exit_code\":0}\n\n")
+		w.(http.Flusher).Flush()
+	})
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := client.RunStreamWithHandler(context.Background(), []string{"echo"}, nil, StreamToWriters(&stdout, &stderr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "out" {
+		t.Fatalf("expected stdout 'out', got %q", stdout.String())
+	}
+	if stderr.String() != "err" {
+		t.Fatalf("expected stderr 'err', got %q", stderr.String())
+	}
+}
@@ -1,5 +1,7 @@
 package agentkernel
 
+import "time"
+
 // SecurityProfile controls sandbox permissions.
 type SecurityProfile string
 
@@ -14,6 +16,16 @@ type RunOptions struct {
 	Image   string          `json:"image,omitempty"`
 	Profile SecurityProfile `json:"profile,omitempty"`
 	Fast    *bool           `json:"fast,omitempty"`
+
+	// Policy, if set, takes precedence over Profile: see Policy for the
+	// fine-grained capability model it describes.
+	Policy *Policy `json:"policy,omitempty"`
+
+	// CacheKey, if set, memoizes this Run call in Client.Cache: a prior
+	// call with the same CacheKey, Image, and command returns the cached
+	// RunOutput without re-executing. Use this for expensive, repeatable
+	// work like compiles or dependency installs.
+	CacheKey string `json:"-"`
 }
 
 // CreateSandboxOptions configures sandbox creation.
@@ -22,6 +34,10 @@ type CreateSandboxOptions struct {
 	VCPUs    int             `json:"vcpus,omitempty"`
 	MemoryMB int             `json:"memory_mb,omitempty"`
 	Profile  SecurityProfile `json:"profile,omitempty"`
+
+	// Policy, if set, takes precedence over Profile: see Policy for the
+	// fine-grained capability model it describes.
+	Policy *Policy `json:"policy,omitempty"`
 }
 
 // RunOutput is the result of a run or exec command.
@@ -40,10 +56,19 @@ type SandboxInfo struct {
 	CreatedAt string `json:"created_at,omitempty"`
 }
 
-// StreamEvent is a server-sent event from a streaming run.
-type StreamEvent struct {
+// RawStreamEvent is an untyped event from a streaming run or exec, as
+// decoded by ParseSSE and parseNDJSON before any schema is applied to the
+// payload. See ParseTypedSSE and the StreamEvent interface for a typed
+// alternative that doesn't require callers to type-assert Data.
+type RawStreamEvent struct {
 	Type string                 `json:"type"`
 	Data map[string]interface{} `json:"data,omitempty"`
+	// ID is the SSE id: field in effect when this event was dispatched, or
+	// "" if the stream hasn't sent one yet. Per the SSE id persists across
+	// events until a new id: line replaces it. RunStream and
+	// SandboxSession.ExecStream use it to resume with Last-Event-ID and
+	// drop the duplicate event a resumed stream typically redelivers.
+	ID string `json:"id,omitempty"`
 }
 
 // apiResponse wraps all API responses.
@@ -59,6 +84,7 @@ type runRequest struct {
 	Image   string          `json:"image,omitempty"`
 	Profile SecurityProfile `json:"profile,omitempty"`
 	Fast    bool            `json:"fast"`
+	Policy  *Policy         `json:"policy,omitempty"`
 }
 
 // createRequest is the POST /sandboxes body.
@@ -68,6 +94,7 @@ type createRequest struct {
 	VCPUs    int             `json:"vcpus,omitempty"`
 	MemoryMB int             `json:"memory_mb,omitempty"`
 	Profile  SecurityProfile `json:"profile,omitempty"`
+	Policy   *Policy         `json:"policy,omitempty"`
 }
 
 // execRequest is the POST /sandboxes/{name}/exec body.
@@ -104,6 +131,90 @@ type fileWriteRequest struct {
 	Encoding string `json:"encoding,omitempty"`
 }
 
+// ManifestEntry describes one file under a sandbox directory, as reported
+// by GET /sandboxes/{name}/manifest. Hash is a hex-encoded sha256 of the
+// file's content, the same digest SandboxSession.Sync computes locally to
+// decide what's changed.
+type ManifestEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	Mode uint32 `json:"mode"`
+}
+
+// SyncOptions configures SandboxSession.Sync.
+type SyncOptions struct {
+	// Ignore holds gitignore-style glob patterns (matched with
+	// path/filepath.Match against each file's slash-separated path
+	// relative to localDir, and against its base name) for files that
+	// should never be uploaded or considered for deletion.
+	Ignore []string
+
+	// Delete removes remote files under remoteDir that have no
+	// corresponding local file. Default: false, so Sync only ever adds
+	// or updates files.
+	Delete bool
+
+	// Concurrency bounds how many local files are hashed concurrently.
+	// Default: 4.
+	Concurrency int
+}
+
+// SyncResult summarizes what SandboxSession.Sync did.
+type SyncResult struct {
+	Uploaded []string
+	Deleted  []string
+	Skipped  []string
+}
+
+// ProgramRequest configures RunProgram, a Go-playground-style helper that
+// builds and runs a small multi-file program inside a fresh sandbox.
+type ProgramRequest struct {
+	// Files maps each source file's path to its content.
+	Files map[string]string
+
+	// Stdin, if set, is piped to the program's standard input.
+	Stdin string
+
+	// Image selects both the sandbox image and, via the substring before
+	// the first ':', the built-in recipe to use ("go", "python", or
+	// "node" — e.g. "python:3.12" uses the python recipe).
+	Image string
+
+	// WithVet runs the language's vet/lint step before building, and
+	// populates ProgramResponse.VetOutput and Events with what it finds.
+	WithVet bool
+
+	// AsTest runs the language's test command instead of building and
+	// running the program. BuildOutput and Stderr are unused; test
+	// output goes to Stdout.
+	AsTest bool
+
+	// BuildTimeout bounds the build/vet/test step. Default: 30s.
+	BuildTimeout time.Duration
+
+	// RunTimeout bounds the run step. Default: 10s. Unused when AsTest.
+	RunTimeout time.Duration
+}
+
+// Diagnostic is one compiler or linter finding, parsed from build or vet
+// output.
+type Diagnostic struct {
+	File    string
+	Line    int
+	Col     int
+	Message string
+}
+
+// ProgramResponse is the result of RunProgram.
+type ProgramResponse struct {
+	BuildOutput string
+	VetOutput   string
+	Stdout      string
+	Stderr      string
+	ExitCode    int
+	Events      []Diagnostic
+}
+
 // batchRunRequest is the POST /batch/run body.
 type batchRunRequest struct {
 	Commands []BatchCommand `json:"commands"`